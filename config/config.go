@@ -2,46 +2,97 @@
 package config
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"github.com/thiruk/logmonster/pkg/types"
 )
 
 // Config represents the application configuration.
 type Config struct {
 	ScanPaths       []string      `mapstructure:"scan_paths"`
+	ScanPathsFile   string        `mapstructure:"scan_paths_file"` // "-" reads stdin
 	ExcludePatterns []string      `mapstructure:"exclude_patterns"`
+	ExcludeDirs     []string      `mapstructure:"exclude_dirs"`  // absolute directory prefixes
+	LogicalFiles    []string      `mapstructure:"logical_files"` // patterns grouping a log with its rotations
 	Scan            ScanConfig    `mapstructure:"scan"`
 	Thresholds      Thresholds    `mapstructure:"thresholds"`
 	Display         DisplayConfig `mapstructure:"display"`
 	Actions         ActionsConfig `mapstructure:"actions"`
+	Audit           AuditConfig   `mapstructure:"audit"`
+
+	// sources records where each key's value came from, for Dump.
+	sources map[string]string
 }
 
 // ScanConfig holds scan-related configuration.
 type ScanConfig struct {
-	Interval       int  `mapstructure:"interval"`
-	MaxDepth       int  `mapstructure:"max_depth"`
-	FollowSymlinks bool `mapstructure:"follow_symlinks"`
+	Interval           time.Duration `mapstructure:"interval"` // "500ms", "2s" or integer seconds
+	Jitter             time.Duration `mapstructure:"jitter"`   // random ± offset between scan cycles
+	MaxDepth           int           `mapstructure:"max_depth"`
+	FollowSymlinks     bool          `mapstructure:"follow_symlinks"` // shortcut for both of the below
+	FollowDirSymlinks  bool          `mapstructure:"follow_dir_symlinks"`
+	FollowFileSymlinks bool          `mapstructure:"follow_file_symlinks"`
+	ResolveSymlinks    bool          `mapstructure:"resolve_symlinks"`  // dedupe symlinks against their targets
+	ReportRemoved      bool          `mapstructure:"report_removed"`    // list files that disappeared
+	MaxFiles           int           `mapstructure:"max_files"`         // 0 = unlimited
+	StayOnDevice       bool          `mapstructure:"stay_on_device"`    // don't cross filesystem boundaries
+	TrackDirs          bool          `mapstructure:"track_dirs"`        // report directories filling with many small files
+	PendingNewFiles    bool          `mapstructure:"pending_new_files"` // re-check new files below threshold next cycle
+	Workers            int           `mapstructure:"workers"`           // 0 = based on CPU count
 }
 
 // Thresholds holds threshold configuration.
 type Thresholds struct {
-	GrowthMB     float64 `mapstructure:"growth_mb"`
-	RateMBPerSec float64 `mapstructure:"rate_mb_per_sec"`
+	GrowthMB       float64 `mapstructure:"growth_mb"`
+	RateMBPerSec   float64 `mapstructure:"rate_mb_per_sec"`
+	ZScore         float64 `mapstructure:"z_score"`         // baseline mode: standard deviations above a file's norm
+	BaselineWindow int     `mapstructure:"baseline_window"` // baseline mode: scans of history kept per file
 }
 
 // DisplayConfig holds display-related configuration.
 type DisplayConfig struct {
-	TopN      int  `mapstructure:"top_n"`
-	UseColors bool `mapstructure:"use_colors"`
+	TopN          int                  `mapstructure:"top_n"`
+	UseColors     bool                 `mapstructure:"use_colors"`
+	PathDisplay   string               `mapstructure:"path_display"` // absolute, relative or basename
+	TimeFormat    string               `mapstructure:"time_format"`  // "default", "rfc3339" or a Go layout
+	UTC           bool                 `mapstructure:"utc"`
+	SortBy        string               `mapstructure:"sort_by"` // rate, bytes, path or final_size
+	SortAscending bool                 `mapstructure:"sort_ascending"`
+	SeverityTiers []SeverityTierConfig `mapstructure:"severity_tiers"` // empty = default
+}
+
+// SeverityTierConfig holds one configured severity tier.
+type SeverityTierConfig struct {
+	Name        string  `mapstructure:"name"`
+	MinMBPerSec float64 `mapstructure:"min_mb_per_sec"`
+	Emoji       string  `mapstructure:"emoji"`
+	Color       string  `mapstructure:"color"`
+}
+
+// AuditConfig holds audit log configuration.
+type AuditConfig struct {
+	Path      string `mapstructure:"path"`        // empty = no audit log
+	MaxSizeMB int    `mapstructure:"max_size_mb"` // rotate beyond this size; 0 = never
 }
 
 // ActionsConfig holds action-related configuration.
 type ActionsConfig struct {
 	KillTimeout        int  `mapstructure:"kill_timeout"`
 	ConfirmDestructive bool `mapstructure:"confirm_destructive"`
+	MaxPerWindow       int  `mapstructure:"max_per_window"`
+	Window             int  `mapstructure:"window"`
+	PIDCooldown        int  `mapstructure:"pid_cooldown"`
 }
 
 // DefaultConfig returns the default configuration.
@@ -50,21 +101,43 @@ func DefaultConfig() *Config {
 		ScanPaths:       []string{"/var/log", "/tmp"},
 		ExcludePatterns: []string{"*.gz", "*.zip", "*.bz2", "*.xz"},
 		Scan: ScanConfig{
-			Interval:       5,
-			MaxDepth:       10,
-			FollowSymlinks: false,
+			Interval:           5 * time.Second,
+			MaxDepth:           10,
+			FollowSymlinks:     false,
+			FollowDirSymlinks:  false,
+			FollowFileSymlinks: false,
+			ResolveSymlinks:    false,
+			ReportRemoved:      false,
+			MaxFiles:           0,
+			StayOnDevice:       false,
+			TrackDirs:          false,
+			PendingNewFiles:    false,
+			Workers:            0,
 		},
 		Thresholds: Thresholds{
-			GrowthMB:     10,
-			RateMBPerSec: 1.0,
+			GrowthMB:       10,
+			RateMBPerSec:   1.0,
+			ZScore:         3.0,
+			BaselineWindow: 30,
 		},
 		Display: DisplayConfig{
-			TopN:      10,
-			UseColors: true,
+			TopN:        10,
+			UseColors:   true,
+			PathDisplay: "absolute",
+			TimeFormat:  "default",
+			SortBy:      "rate",
+			UTC:         false,
 		},
 		Actions: ActionsConfig{
 			KillTimeout:        5,
 			ConfirmDestructive: true,
+			MaxPerWindow:       3,
+			Window:             60,
+			PIDCooldown:        300,
+		},
+		Audit: AuditConfig{
+			Path:      "",
+			MaxSizeMB: 100,
 		},
 	}
 }
@@ -87,16 +160,41 @@ func Load() (*Config, error) {
 
 	// Set defaults
 	viper.SetDefault("scan_paths", cfg.ScanPaths)
+	viper.SetDefault("scan_paths_file", cfg.ScanPathsFile)
 	viper.SetDefault("exclude_patterns", cfg.ExcludePatterns)
+	viper.SetDefault("exclude_dirs", cfg.ExcludeDirs)
+	viper.SetDefault("logical_files", cfg.LogicalFiles)
 	viper.SetDefault("scan.interval", cfg.Scan.Interval)
+	viper.SetDefault("scan.jitter", cfg.Scan.Jitter)
 	viper.SetDefault("scan.max_depth", cfg.Scan.MaxDepth)
 	viper.SetDefault("scan.follow_symlinks", cfg.Scan.FollowSymlinks)
+	viper.SetDefault("scan.follow_dir_symlinks", cfg.Scan.FollowDirSymlinks)
+	viper.SetDefault("scan.follow_file_symlinks", cfg.Scan.FollowFileSymlinks)
+	viper.SetDefault("scan.resolve_symlinks", cfg.Scan.ResolveSymlinks)
+	viper.SetDefault("scan.report_removed", cfg.Scan.ReportRemoved)
+	viper.SetDefault("scan.track_dirs", cfg.Scan.TrackDirs)
+	viper.SetDefault("scan.pending_new_files", cfg.Scan.PendingNewFiles)
+	viper.SetDefault("scan.max_files", cfg.Scan.MaxFiles)
+	viper.SetDefault("scan.stay_on_device", cfg.Scan.StayOnDevice)
+	viper.SetDefault("scan.workers", cfg.Scan.Workers)
 	viper.SetDefault("thresholds.growth_mb", cfg.Thresholds.GrowthMB)
 	viper.SetDefault("thresholds.rate_mb_per_sec", cfg.Thresholds.RateMBPerSec)
+	viper.SetDefault("thresholds.z_score", cfg.Thresholds.ZScore)
+	viper.SetDefault("thresholds.baseline_window", cfg.Thresholds.BaselineWindow)
 	viper.SetDefault("display.top_n", cfg.Display.TopN)
 	viper.SetDefault("display.use_colors", cfg.Display.UseColors)
+	viper.SetDefault("display.path_display", cfg.Display.PathDisplay)
+	viper.SetDefault("display.time_format", cfg.Display.TimeFormat)
+	viper.SetDefault("display.sort_by", cfg.Display.SortBy)
+	viper.SetDefault("display.sort_ascending", cfg.Display.SortAscending)
+	viper.SetDefault("display.utc", cfg.Display.UTC)
 	viper.SetDefault("actions.kill_timeout", cfg.Actions.KillTimeout)
 	viper.SetDefault("actions.confirm_destructive", cfg.Actions.ConfirmDestructive)
+	viper.SetDefault("actions.max_per_window", cfg.Actions.MaxPerWindow)
+	viper.SetDefault("actions.window", cfg.Actions.Window)
+	viper.SetDefault("actions.pid_cooldown", cfg.Actions.PIDCooldown)
+	viper.SetDefault("audit.path", cfg.Audit.Path)
+	viper.SetDefault("audit.max_size_mb", cfg.Audit.MaxSizeMB)
 
 	// Read config file (ignore if not found)
 	if err := viper.ReadInConfig(); err != nil {
@@ -106,16 +204,147 @@ func Load() (*Config, error) {
 	}
 
 	// Unmarshal to struct
-	if err := viper.Unmarshal(cfg); err != nil {
+	if err := viper.Unmarshal(cfg, viper.DecodeHook(decodeHook())); err != nil {
+		return nil, err
+	}
+
+	cfg.sources = make(map[string]string)
+	walkFields(reflect.ValueOf(cfg).Elem(), "", func(key string, _ reflect.Value) {
+		cfg.sources[key] = viperSource(key)
+	})
+
+	if cfg.ScanPathsFile != "" {
+		paths, err := readPathsFile(cfg.ScanPathsFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ScanPaths = mergePaths(cfg.ScanPaths, paths)
+		cfg.sources["scan_paths"] += " + scan_paths_file"
+	}
+
+	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
+// readPathsFile reads newline-separated paths from a file, or from stdin
+// when name is "-".
+func readPathsFile(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open scan paths file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return parsePaths(r)
+}
+
+// parsePaths parses one path per line, ignoring blank lines and # comments.
+func parsePaths(r io.Reader) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// mergePaths appends extra paths to base, skipping duplicates.
+func mergePaths(base, extra []string) []string {
+	seen := make(map[string]bool, len(base))
+	merged := make([]string, 0, len(base)+len(extra))
+	for _, p := range append(base, extra...) {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+// SeverityScheme returns the configured severity scheme, or the default
+// scheme when none is configured.
+func (c *Config) SeverityScheme() types.SeverityScheme {
+	if len(c.Display.SeverityTiers) == 0 {
+		return types.DefaultSeverityScheme()
+	}
+
+	tiers := make([]types.SeverityTier, 0, len(c.Display.SeverityTiers))
+	for _, t := range c.Display.SeverityTiers {
+		tiers = append(tiers, types.SeverityTier{
+			Name:    t.Name,
+			MinRate: t.MinMBPerSec * 1024 * 1024,
+			Emoji:   t.Emoji,
+			Color:   t.Color,
+		})
+	}
+	sort.SliceStable(tiers, func(i, j int) bool {
+		return tiers[i].MinRate < tiers[j].MinRate
+	})
+
+	return types.SeverityScheme{Tiers: tiers}
+}
+
+// decodeHook converts config values while unmarshalling. Durations accept
+// time.ParseDuration strings as well as plain numbers of seconds, so older
+// integer intervals keep working.
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		durationHook,
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+// durationHook decodes numbers as seconds and strings via time.ParseDuration.
+func durationHook(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if to != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+
+	switch v := data.(type) {
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		if secs, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(secs * float64(time.Second)), nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q: %w", v, err)
+		}
+		return d, nil
+	default:
+		return data, nil
+	}
+}
+
 // GetScanInterval returns the scan interval as a duration.
 func (c *Config) GetScanInterval() time.Duration {
-	return time.Duration(c.Scan.Interval) * time.Second
+	return c.Scan.Interval
+}
+
+// GetFollowSymlinks returns whether symlinked directories and files are
+// followed, with follow_symlinks enabling both.
+func (c *Config) GetFollowSymlinks() (dirs, files bool) {
+	return c.Scan.FollowSymlinks || c.Scan.FollowDirSymlinks,
+		c.Scan.FollowSymlinks || c.Scan.FollowFileSymlinks
 }
 
 // GetThresholdBytes returns the threshold in bytes.
@@ -127,3 +356,18 @@ func (c *Config) GetThresholdBytes() int64 {
 func (c *Config) GetKillTimeout() time.Duration {
 	return time.Duration(c.Actions.KillTimeout) * time.Second
 }
+
+// GetActionWindow returns the action rate-limit window as a duration.
+func (c *Config) GetActionWindow() time.Duration {
+	return time.Duration(c.Actions.Window) * time.Second
+}
+
+// GetAuditMaxSize returns the audit log rotation size in bytes.
+func (c *Config) GetAuditMaxSize() int64 {
+	return int64(c.Audit.MaxSizeMB) * 1024 * 1024
+}
+
+// GetPIDCooldown returns the per-PID action cooldown as a duration.
+func (c *Config) GetPIDCooldown() time.Duration {
+	return time.Duration(c.Actions.PIDCooldown) * time.Second
+}
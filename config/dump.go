@@ -0,0 +1,96 @@
+package config
+
+import (
+	"bytes"
+	"reflect"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Sources of a configuration value, as reported by Dump.
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+)
+
+// viperSource reports where viper found the value for key.
+func viperSource(key string) string {
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// Dump renders the effective configuration as YAML, annotating each value
+// with where it came from.
+func (c *Config) Dump() string {
+	root := c.dumpStruct(reflect.ValueOf(c).Elem(), "")
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return ""
+	}
+	enc.Close()
+
+	return buf.String()
+}
+
+// dumpStruct builds a YAML mapping for a config struct.
+func (c *Config) dumpStruct(v reflect.Value, prefix string) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := fieldKey(t.Field(i))
+		if name == "" {
+			continue
+		}
+		key := joinKey(prefix, name)
+		fv := v.Field(i)
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: name}
+		var valueNode *yaml.Node
+		if fv.Kind() == reflect.Struct {
+			valueNode = c.dumpStruct(fv, key)
+		} else {
+			valueNode = dumpValue(fv)
+			// yaml.v3 misplaces line comments on block sequences, so
+			// annotate the key instead.
+			if valueNode.Kind == yaml.ScalarNode {
+				valueNode.LineComment = c.source(key)
+			} else {
+				keyNode.LineComment = c.source(key)
+			}
+		}
+
+		node.Content = append(node.Content, keyNode, valueNode)
+	}
+
+	return node
+}
+
+// dumpValue encodes a leaf value as a YAML node.
+func dumpValue(v reflect.Value) *yaml.Node {
+	var value interface{} = v.Interface()
+	if v.Type() == durationType {
+		value = time.Duration(v.Int()).String()
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(value); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: ""}
+	}
+	return node
+}
+
+// source returns where key's value came from.
+func (c *Config) source(key string) string {
+	if src, ok := c.sources[key]; ok {
+		return src
+	}
+	return SourceDefault
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaURI identifies the JSON Schema draft emitted by Schema.
+const schemaURI = "https://json-schema.org/draft/2020-12/schema"
+
+// minimums holds the lower bound for numeric config keys. It drives both
+// Validate and the generated JSON Schema so the two cannot drift.
+var minimums = map[string]float64{
+	"scan.max_depth":             0,
+	"scan.workers":               0,
+	"scan.max_files":             0,
+	"thresholds.growth_mb":       0,
+	"thresholds.rate_mb_per_sec": 0,
+	"thresholds.z_score":         0,
+	"display.top_n":              1,
+	"actions.kill_timeout":       1,
+	"actions.max_per_window":     1,
+	"actions.window":             1,
+	"actions.pid_cooldown":       0,
+	"audit.max_size_mb":          0,
+}
+
+// minDurations holds the lower bound for duration config keys.
+var minDurations = map[string]time.Duration{
+	"scan.interval": 100 * time.Millisecond,
+	"scan.jitter":   0,
+}
+
+// durationType is the reflect type of time.Duration.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Validate checks the configuration against the validation rules.
+func (c *Config) Validate() error {
+	var err error
+	walkFields(reflect.ValueOf(c).Elem(), "", func(key string, v reflect.Value) {
+		if err != nil {
+			return
+		}
+		if v.Type() == durationType {
+			if min, ok := minDurations[key]; ok && time.Duration(v.Int()) < min {
+				err = fmt.Errorf("invalid config: %s must be >= %s, got %s", key, min, time.Duration(v.Int()))
+			}
+			return
+		}
+		min, ok := minimums[key]
+		if !ok {
+			return
+		}
+		var n float64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Float64:
+			n = v.Float()
+		default:
+			return
+		}
+		if n < min {
+			err = fmt.Errorf("invalid config: %s must be >= %v, got %v", key, min, n)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	switch c.Display.PathDisplay {
+	case "", "absolute", "relative", "basename":
+	default:
+		return fmt.Errorf("invalid config: display.path_display must be absolute, relative or basename, got %q", c.Display.PathDisplay)
+	}
+
+	switch c.Display.SortBy {
+	case "", "rate", "bytes", "path", "final_size":
+	default:
+		return fmt.Errorf("invalid config: display.sort_by must be rate, bytes, path or final_size, got %q", c.Display.SortBy)
+	}
+
+	for _, p := range c.ScanPaths {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid config: scan_paths entry %q is not a valid glob: %w", p, err)
+		}
+	}
+
+	for _, p := range c.LogicalFiles {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid config: logical_files entry %q is not a valid glob: %w", p, err)
+		}
+	}
+
+	for _, dir := range c.ExcludeDirs {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("invalid config: exclude_dirs entries must be absolute, got %q", dir)
+		}
+	}
+	return nil
+}
+
+// Schema returns a JSON Schema describing the configuration file, including
+// defaults and validation constraints.
+func Schema() ([]byte, error) {
+	defaults := reflect.ValueOf(DefaultConfig()).Elem()
+
+	schema := objectSchema(defaults, "")
+	schema["$schema"] = schemaURI
+	schema["title"] = "logmonster configuration"
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// objectSchema builds the schema for a struct value, using its field values
+// as defaults.
+func objectSchema(v reflect.Value, prefix string) map[string]interface{} {
+	properties := make(map[string]interface{})
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := fieldKey(field)
+		if name == "" {
+			continue
+		}
+		key := joinKey(prefix, name)
+		fv := v.Field(i)
+
+		if fv.Kind() == reflect.Struct {
+			properties[name] = objectSchema(fv, key)
+			continue
+		}
+
+		prop := valueSchema(fv)
+		if min, ok := minimums[key]; ok {
+			prop["minimum"] = min
+		}
+		if min, ok := minDurations[key]; ok {
+			prop["description"] = fmt.Sprintf("%s; minimum %s", prop["description"], min)
+		}
+		properties[name] = prop
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// valueSchema returns the schema for a leaf value with its default.
+func valueSchema(v reflect.Value) map[string]interface{} {
+	if v.Type() == durationType {
+		return map[string]interface{}{
+			"type":        []string{"string", "number"},
+			"default":     time.Duration(v.Int()).String(),
+			"description": "duration such as \"500ms\" or \"2s\", or a number of seconds",
+		}
+	}
+
+	prop := map[string]interface{}{
+		"default": v.Interface(),
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		prop["type"] = "string"
+	case reflect.Bool:
+		prop["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		prop["type"] = "integer"
+	case reflect.Float64:
+		prop["type"] = "number"
+	case reflect.Slice:
+		prop["type"] = "array"
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if elem.Kind() == reflect.Struct {
+			prop["items"] = objectSchema(elem, "")
+		} else {
+			prop["items"] = map[string]interface{}{"type": "string"}
+		}
+	}
+
+	return prop
+}
+
+// walkFields calls fn for every leaf field of a config struct, keyed by its
+// dotted mapstructure path.
+func walkFields(v reflect.Value, prefix string, fn func(key string, v reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := fieldKey(t.Field(i))
+		if name == "" {
+			continue
+		}
+		key := joinKey(prefix, name)
+		if v.Field(i).Kind() == reflect.Struct {
+			walkFields(v.Field(i), key, fn)
+			continue
+		}
+		fn(key, v.Field(i))
+	}
+}
+
+// fieldKey returns the mapstructure key of a field, or "" if it has none.
+func fieldKey(field reflect.StructField) string {
+	tag := field.Tag.Get("mapstructure")
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
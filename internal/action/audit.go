@@ -0,0 +1,20 @@
+package action
+
+import "github.com/thiruk/logmonster/internal/audit"
+
+// auditLog receives an event for every action taken; nil disables auditing.
+var auditLog *audit.Log
+
+// SetAuditLog sets the audit log that actions are recorded to.
+func SetAuditLog(l *audit.Log) {
+	auditLog = l
+}
+
+// record writes an action and its outcome to the audit log. A failed write
+// doesn't undo the action, so it is left for audit.Log.Err to report.
+func record(e audit.Event, err error) {
+	if err != nil {
+		e.Error = err.Error()
+	}
+	_ = auditLog.Record(e)
+}
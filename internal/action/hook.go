@@ -0,0 +1,216 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/thiruk/logmonster/internal/audit"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// CommandHook runs an external command for a growing file, e.g. to open a
+// ticket or rotate the log. Template is expanded with text/template and the
+// result is split into words like a shell would: single and double quotes
+// group words and a backslash escapes the next character. The command runs
+// directly, not through a shell, and placeholder values are escaped before
+// splitting, so a file name with spaces or quotes stays one argument and
+// can't inject extra commands. Point the template at a script when shell
+// features are needed.
+//
+// Available placeholders:
+//
+//	{{.Path}}    path of the growing file
+//	{{.PID}}     writer PID, 0 if unknown
+//	{{.Process}} writer process name
+//	{{.Rate}}    growth rate in bytes per second
+//	{{.Growth}}  bytes grown over the interval
+type CommandHook struct {
+	Template string
+	Timeout  time.Duration // 0 = no timeout
+}
+
+// hookData is the template data for a CommandHook.
+type hookData struct {
+	Path    string
+	PID     int32
+	Process string
+	Rate    float64
+	Growth  int64
+}
+
+// HookError reports a hook command that failed, with what it printed.
+type HookError struct {
+	Args     []string
+	ExitCode int // -1 if the command didn't run to completion
+	Output   []byte
+	Err      error
+}
+
+func (e *HookError) Error() string {
+	msg := fmt.Sprintf("hook %q failed", strings.Join(e.Args, " "))
+	if e.ExitCode >= 0 {
+		msg += fmt.Sprintf(" with exit code %d", e.ExitCode)
+	} else if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	if out := strings.TrimSpace(string(e.Output)); out != "" {
+		msg += ": " + out
+	}
+	return msg
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// Run expands the template for g and the writing process (which may be
+// nil) and executes the command. A non-zero exit or timeout is returned as
+// a *HookError carrying the exit code and combined output.
+func (h CommandHook) Run(g types.FileGrowth, info *types.ProcessInfo) (err error) {
+	defer func() {
+		e := audit.Event{Type: audit.HookRun, Path: g.Path, Detail: h.Template}
+		if info != nil {
+			e.PID = info.PID
+		}
+		record(e, err)
+	}()
+
+	data := hookData{
+		Path:   escapeArg(g.Path),
+		Rate:   g.GrowthRate,
+		Growth: g.GrowthBytes,
+	}
+	if info != nil {
+		data.PID = info.PID
+		data.Process = escapeArg(info.Name)
+	}
+
+	args, err := h.expand(data)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	if err := cmd.Run(); err != nil {
+		hookErr := &HookError{Args: args, ExitCode: -1, Output: out.Bytes(), Err: err}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && ctx.Err() == nil {
+			hookErr.ExitCode = exitErr.ExitCode()
+		}
+		if ctx.Err() != nil {
+			hookErr.Err = ctx.Err()
+		}
+		return hookErr
+	}
+
+	return nil
+}
+
+// expand executes the template and splits the result into arguments.
+func (h CommandHook) expand(data hookData) ([]string, error) {
+	tmpl, err := template.New("hook").Option("missingkey=error").Parse(h.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hook template %q: %w", h.Template, err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("failed to expand hook template %q: %w", h.Template, err)
+	}
+
+	args, err := splitArgs(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid hook command %q: %w", h.Template, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty hook command")
+	}
+	return args, nil
+}
+
+// escapeArg backslash-escapes s so splitArgs reads it back unchanged
+// outside single quotes.
+func escapeArg(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r == '\\' || r == '\'' || r == '"' || unicode.IsSpace(r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// splitArgs splits s into words on unquoted whitespace. Single quotes keep
+// everything up to the closing quote literally; inside double quotes and
+// outside quotes a backslash escapes the next character.
+func splitArgs(s string) ([]string, error) {
+	var (
+		args    []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case unicode.IsSpace(r):
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
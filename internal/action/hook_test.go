@@ -0,0 +1,88 @@
+package action
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandHookExpand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		path     string
+		want     []string
+	}{
+		{
+			name:     "plain",
+			template: "rotate {{.Path}} {{.Growth}}",
+			path:     "/var/log/app.log",
+			want:     []string{"rotate", "/var/log/app.log", "42"},
+		},
+		{
+			name:     "action spanning spaces",
+			template: `notify {{printf "%s:%d" .Path .Growth}}`,
+			path:     "/var/log/app.log",
+			want:     []string{"notify", "/var/log/app.log:42"},
+		},
+		{
+			name:     "path with spaces stays one argument",
+			template: "rotate {{.Path}}",
+			path:     "/var/log/my app.log",
+			want:     []string{"rotate", "/var/log/my app.log"},
+		},
+		{
+			name:     "path can't inject arguments",
+			template: "rotate {{.Path}}",
+			path:     `/tmp/x' "y" \z`,
+			want:     []string{"rotate", `/tmp/x' "y" \z`},
+		},
+		{
+			name:     "path inside double quotes",
+			template: `echo "grew: {{.Path}}"`,
+			path:     "/var/log/my app.log",
+			want:     []string{"echo", "grew: /var/log/my app.log"},
+		},
+		{
+			name:     "single quotes are literal",
+			template: `sh -c 'gzip "$0"' {{.Path}}`,
+			path:     "/var/log/app.log",
+			want:     []string{"sh", "-c", `gzip "$0"`, "/var/log/app.log"},
+		},
+		{
+			name:     "empty quotes",
+			template: `cmd "" x`,
+			path:     "/a",
+			want:     []string{"cmd", "", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CommandHook{Template: tt.template}.expand(hookData{
+				Path:   escapeArg(tt.path),
+				Growth: 42,
+			})
+			if err != nil {
+				t.Fatalf("expand: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandHookExpandErrors(t *testing.T) {
+	for _, template := range []string{
+		"",
+		"   ",
+		"{{.Path",
+		"{{.Missing}}",
+		`echo "unterminated`,
+		`echo trailing\`,
+	} {
+		if _, err := (CommandHook{Template: template}).expand(hookData{}); err == nil {
+			t.Errorf("expand(%q) succeeded, want error", template)
+		}
+	}
+}
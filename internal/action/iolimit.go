@@ -0,0 +1,8 @@
+package action
+
+// IOLimit records an io.max change so it can be reverted.
+type IOLimit struct {
+	CgroupPath string // cgroup directory under /sys/fs/cgroup
+	Device     string // "major:minor" of the backing disk
+	Previous   string // previous io.max settings for the device
+}
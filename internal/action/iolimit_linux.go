@@ -0,0 +1,129 @@
+//go:build linux
+
+package action
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thiruk/logmonster/internal/audit"
+	"golang.org/x/sys/unix"
+)
+
+// cgroupRoot is where the unified cgroup v2 hierarchy is mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// unlimitedIO is the io.max value meaning no limit.
+const unlimitedIO = "rbps=max wbps=max riops=max wiops=max"
+
+// SetIOLimit caps read and write throughput of the cgroup containing pid to
+// mbps for the disk backing filePath, using cgroup v2 io.max. The returned
+// IOLimit can be passed to RestoreIOLimit to revert the change.
+func SetIOLimit(pid int32, filePath string, mbps int) (*IOLimit, error) {
+	if mbps <= 0 {
+		return nil, fmt.Errorf("invalid IO limit: %d MB/s", mbps)
+	}
+
+	cgroup, err := cgroupPath(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	device, err := blockDevice(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ioMax := filepath.Join(cgroup, "io.max")
+	previous, err := readIOMax(ioMax, device)
+	if err != nil {
+		return nil, err
+	}
+
+	bps := int64(mbps) * 1024 * 1024
+	line := fmt.Sprintf("%s rbps=%d wbps=%d", device, bps, bps)
+	err = os.WriteFile(ioMax, []byte(line), 0644)
+	record(audit.Event{Type: audit.IOLimited, PID: pid, Path: filePath, Detail: line}, err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ioMax, err)
+	}
+
+	return &IOLimit{CgroupPath: cgroup, Device: device, Previous: previous}, nil
+}
+
+// RestoreIOLimit reverts a limit applied by SetIOLimit.
+func RestoreIOLimit(limit *IOLimit) error {
+	ioMax := filepath.Join(limit.CgroupPath, "io.max")
+	line := limit.Device + " " + limit.Previous
+	err := os.WriteFile(ioMax, []byte(line), 0644)
+	record(audit.Event{Type: audit.IOLimited, Detail: "restore " + line}, err)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", ioMax, err)
+	}
+	return nil
+}
+
+// cgroupPath returns the cgroup v2 directory for a process.
+func cgroupPath(pid int32) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", fmt.Errorf("process not found: %d", pid)
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if rel, ok := strings.CutPrefix(line, "0::"); ok {
+			return filepath.Join(cgroupRoot, rel), nil
+		}
+	}
+
+	return "", fmt.Errorf("process %d is not in a cgroup v2 hierarchy", pid)
+}
+
+// blockDevice returns "major:minor" of the whole disk holding path.
+// io.max only accepts whole disks, so partitions resolve to their parent.
+func blockDevice(path string) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", err
+	}
+	device := fmt.Sprintf("%d:%d", unix.Major(st.Dev), unix.Minor(st.Dev))
+
+	sysPath, err := filepath.EvalSymlinks(filepath.Join("/sys/dev/block", device))
+	if err != nil {
+		return "", fmt.Errorf("%s is not on a block device", path)
+	}
+
+	if _, err := os.Stat(filepath.Join(sysPath, "partition")); err == nil {
+		parent, err := os.ReadFile(filepath.Join(filepath.Dir(sysPath), "dev"))
+		if err != nil {
+			return "", err
+		}
+		device = strings.TrimSpace(string(parent))
+	}
+
+	return device, nil
+}
+
+// readIOMax returns the current io.max settings for device.
+func readIOMax(ioMax, device string) (string, error) {
+	f, err := os.Open(ioMax)
+	if err != nil {
+		return "", fmt.Errorf("io controller not available: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rest, ok := strings.CutPrefix(scanner.Text(), device+" "); ok {
+			return rest, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return unlimitedIO, nil
+}
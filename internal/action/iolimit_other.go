@@ -0,0 +1,15 @@
+//go:build !linux
+
+package action
+
+import "fmt"
+
+// SetIOLimit requires cgroup v2 and is only supported on Linux.
+func SetIOLimit(pid int32, filePath string, mbps int) (*IOLimit, error) {
+	return nil, fmt.Errorf("IO limiting is only supported on Linux")
+}
+
+// RestoreIOLimit requires cgroup v2 and is only supported on Linux.
+func RestoreIOLimit(limit *IOLimit) error {
+	return fmt.Errorf("IO limiting is only supported on Linux")
+}
@@ -2,12 +2,21 @@
 package action
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"syscall"
 	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/thiruk/logmonster/internal/audit"
 )
 
+// ErrPIDReused is returned when a PID now belongs to a different process
+// than the one that was identified.
+var ErrPIDReused = errors.New("PID reused by a different process")
+
 // Killer handles process termination.
 type Killer struct {
 	Timeout time.Duration
@@ -20,12 +29,24 @@ func NewKiller(timeout time.Duration) *Killer {
 
 // Kill terminates a process gracefully, then forcefully if needed.
 func (k *Killer) Kill(pid int32) error {
+	return k.KillContext(context.Background(), pid)
+}
+
+// KillContext terminates a process like Kill, but stops waiting and returns
+// ctx.Err() without escalating to SIGKILL if ctx is cancelled during the
+// SIGTERM grace period.
+func (k *Killer) KillContext(ctx context.Context, pid int32) (err error) {
+	defer func() { record(audit.Event{Type: audit.ProcessKilled, PID: pid}, err) }()
+
 	// Check if process exists
 	proc, err := os.FindProcess(int(pid))
 	if err != nil {
 		return fmt.Errorf("process not found: %d", pid)
 	}
 
+	// Remember the start time so a reused PID isn't mistaken for our process
+	started := processStartTime(pid)
+
 	// Send SIGTERM
 	if err := proc.Signal(syscall.SIGTERM); err != nil {
 		if err == os.ErrProcessDone {
@@ -35,37 +56,78 @@ func (k *Killer) Kill(pid int32) error {
 	}
 
 	// Wait for process to exit
-	done := make(chan bool, 1)
-	go func() {
-		for {
-			if !k.processExists(pid) {
-				done <- true
-				return
-			}
-			time.Sleep(100 * time.Millisecond)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.NewTimer(k.Timeout)
+	defer timeout.Stop()
+
+	for {
+		if !k.processRunning(pid, started) {
+			return nil
 		}
-	}()
 
-	select {
-	case <-done:
-		return nil
-	case <-time.After(k.Timeout):
-		// Send SIGKILL
-		if err := proc.Signal(syscall.SIGKILL); err != nil {
-			if err == os.ErrProcessDone {
-				return nil
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-timeout.C:
+			// Send SIGKILL
+			if err := proc.Signal(syscall.SIGKILL); err != nil {
+				if err == os.ErrProcessDone {
+					return nil
+				}
+				return fmt.Errorf("failed to send SIGKILL: %w", err)
 			}
-			return fmt.Errorf("failed to send SIGKILL: %w", err)
-		}
-		// Wait a bit more for SIGKILL
-		time.Sleep(500 * time.Millisecond)
-		if k.processExists(pid) {
-			return fmt.Errorf("process %d still running after SIGKILL", pid)
+			// Wait a bit more for SIGKILL
+			time.Sleep(500 * time.Millisecond)
+			if k.processRunning(pid, started) {
+				return fmt.Errorf("process %d still running after SIGKILL", pid)
+			}
+			return nil
 		}
-		return nil
 	}
 }
 
+// KillIfStartTime kills pid like Kill, but only if the process still has
+// the start time captured when it was identified (ProcessInfo.StartTime).
+// If the PID has exited and been reused in the meantime, nothing is
+// signalled and ErrPIDReused is returned.
+func (k *Killer) KillIfStartTime(pid int32, expectedStart time.Time) error {
+	if err := checkStartTime(pid, expectedStart); err != nil {
+		return err
+	}
+	return k.Kill(pid)
+}
+
+// checkStartTime verifies that pid still started at expectedStart.
+func checkStartTime(pid int32, expectedStart time.Time) error {
+	started := processStartTime(pid)
+	if started == 0 {
+		return fmt.Errorf("process not found: %d", pid)
+	}
+	if started != expectedStart.UnixMilli() {
+		return fmt.Errorf("%w: pid %d started at %s, expected %s", ErrPIDReused, pid,
+			time.UnixMilli(started).Format(time.RFC3339), expectedStart.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// processRunning checks if a process is still running. Zombies count as
+// exited, and when started is non-zero a process with a different start
+// time is treated as a reused PID.
+func (k *Killer) processRunning(pid int32, started int64) bool {
+	if !k.processExists(pid) {
+		return false
+	}
+	if state, err := k.ProcessState(pid); err == nil && state == "Z" {
+		return false
+	}
+	if started != 0 && processStartTime(pid) != started {
+		return false
+	}
+	return true
+}
+
 // processExists checks if a process is still running.
 func (k *Killer) processExists(pid int32) bool {
 	proc, err := os.FindProcess(int(pid))
@@ -77,8 +139,26 @@ func (k *Killer) processExists(pid int32) bool {
 	return err == nil
 }
 
+// processStartTime returns a process's creation time in milliseconds since
+// the epoch, or 0 if it can't be determined.
+func processStartTime(pid int32) int64 {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return 0
+	}
+	created, err := proc.CreateTime()
+	if err != nil {
+		return 0
+	}
+	return created
+}
+
 // SendSignal sends a specific signal to a process.
-func (k *Killer) SendSignal(pid int32, sig syscall.Signal) error {
+func (k *Killer) SendSignal(pid int32, sig syscall.Signal) (err error) {
+	defer func() {
+		record(audit.Event{Type: audit.ProcessSignalled, PID: pid, Detail: sig.String()}, err)
+	}()
+
 	proc, err := os.FindProcess(int(pid))
 	if err != nil {
 		return fmt.Errorf("process not found: %d", pid)
@@ -0,0 +1,110 @@
+package action
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrRateLimited is returned when a Limiter refuses a destructive action.
+var ErrRateLimited = errors.New("action rate limited")
+
+// Limiter wraps a Killer and caps destructive actions using a token bucket
+// shared by all PIDs plus a cooldown per PID.
+type Limiter struct {
+	killer   *Killer
+	max      int
+	window   time.Duration
+	cooldown time.Duration
+
+	mu       sync.Mutex
+	tokens   float64
+	last     time.Time
+	lastSeen map[int32]time.Time
+}
+
+// NewLimiter creates a Limiter allowing at most max actions per window and
+// one action per PID every cooldown.
+func NewLimiter(killer *Killer, max int, window, cooldown time.Duration) *Limiter {
+	if max <= 0 {
+		max = 1
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	return &Limiter{
+		killer:   killer,
+		max:      max,
+		window:   window,
+		cooldown: cooldown,
+		tokens:   float64(max),
+		last:     time.Now(),
+		lastSeen: make(map[int32]time.Time),
+	}
+}
+
+// Allow reserves an action against pid, returning ErrRateLimited if either
+// the global budget or the PID's cooldown would be exceeded.
+func (l *Limiter) Allow(pid int32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	// Refill the bucket
+	elapsed := now.Sub(l.last)
+	l.last = now
+	l.tokens += elapsed.Seconds() * float64(l.max) / l.window.Seconds()
+	if l.tokens > float64(l.max) {
+		l.tokens = float64(l.max)
+	}
+
+	if last, ok := l.lastSeen[pid]; ok && now.Sub(last) < l.cooldown {
+		return fmt.Errorf("%w: pid %d in cooldown", ErrRateLimited, pid)
+	}
+	if l.tokens < 1 {
+		return fmt.Errorf("%w: more than %d actions in %s", ErrRateLimited, l.max, l.window)
+	}
+
+	l.tokens--
+
+	// Drop expired cooldown entries
+	for p, t := range l.lastSeen {
+		if now.Sub(t) >= l.cooldown {
+			delete(l.lastSeen, p)
+		}
+	}
+	l.lastSeen[pid] = now
+
+	return nil
+}
+
+// Kill terminates a process if the limiter allows it.
+func (l *Limiter) Kill(pid int32) error {
+	if err := l.Allow(pid); err != nil {
+		return err
+	}
+	return l.killer.Kill(pid)
+}
+
+// KillIfStartTime kills a process if the limiter allows it and its start
+// time still matches expectedStart. A reused PID doesn't consume the budget.
+func (l *Limiter) KillIfStartTime(pid int32, expectedStart time.Time) error {
+	if err := checkStartTime(pid, expectedStart); err != nil {
+		return err
+	}
+	if err := l.Allow(pid); err != nil {
+		return err
+	}
+	return l.killer.KillIfStartTime(pid, expectedStart)
+}
+
+// SendSignal sends a signal to a process if the limiter allows it.
+func (l *Limiter) SendSignal(pid int32, sig syscall.Signal) error {
+	if err := l.Allow(pid); err != nil {
+		return err
+	}
+	return l.killer.SendSignal(pid, sig)
+}
@@ -0,0 +1,54 @@
+package action
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thiruk/logmonster/internal/resolver"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Remediation is the suggested action for a growing file: stop its service
+// when every writer belongs to one systemd unit, otherwise kill the writers.
+type Remediation struct {
+	Unit string  // set when the whole service should be stopped
+	PIDs []int32 // writers to kill when Unit is empty
+}
+
+// String describes the remediation for a prompt.
+func (r Remediation) String() string {
+	if r.Unit != "" {
+		return fmt.Sprintf("stop service %s", r.Unit)
+	}
+
+	pids := make([]string, len(r.PIDs))
+	for i, pid := range r.PIDs {
+		pids[i] = fmt.Sprintf("%d", pid)
+	}
+	return fmt.Sprintf("kill %d process(es): %s", len(r.PIDs), strings.Join(pids, ", "))
+}
+
+// PlanRemediation chooses how to stop the writers of a growing file. A
+// service is only suggested when systemd itself reported the unit for every
+// writer; units guessed from the process tree fall back to killing PIDs.
+func PlanRemediation(attr types.FileAttribution) Remediation {
+	var plan Remediation
+	for _, p := range attr.Processes {
+		plan.PIDs = append(plan.PIDs, p.PID)
+	}
+
+	if len(attr.Processes) == 0 || len(attr.Services) != 1 {
+		return plan
+	}
+	svc := attr.Services[0]
+	if svc.Status == resolver.FallbackStatus {
+		return plan
+	}
+	for _, p := range attr.Processes {
+		if p.Unit != svc.Unit {
+			return plan
+		}
+	}
+
+	return Remediation{Unit: svc.Unit}
+}
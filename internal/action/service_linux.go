@@ -0,0 +1,32 @@
+//go:build linux
+
+package action
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/thiruk/logmonster/internal/audit"
+)
+
+// StopService asks systemd to stop a unit over D-Bus. systemd handles
+// dependencies and stops every process in the unit, so nothing restarts the
+// way a killed child of a service would.
+func StopService(unit string) (err error) {
+	defer func() { record(audit.Event{Type: audit.ServiceStopped, Unit: unit}, err) }()
+
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return fmt.Errorf("failed to connect to system bus: %w", err)
+	}
+	defer conn.Close()
+
+	obj := conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
+
+	var job dbus.ObjectPath
+	if err := obj.Call("org.freedesktop.systemd1.Manager.StopUnit", 0, unit, "replace").Store(&job); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", unit, err)
+	}
+
+	return nil
+}
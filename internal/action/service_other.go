@@ -0,0 +1,16 @@
+//go:build !linux
+
+package action
+
+import (
+	"fmt"
+
+	"github.com/thiruk/logmonster/internal/audit"
+)
+
+// StopService requires systemd and is only supported on Linux.
+func StopService(unit string) (err error) {
+	defer func() { record(audit.Event{Type: audit.ServiceStopped, Unit: unit}, err) }()
+
+	return fmt.Errorf("stopping services is only supported on Linux")
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package action
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ProcessState returns the single-letter scheduler state of a process from
+// /proc/[pid]/stat, such as "R", "S" or "Z" for a zombie.
+func (k *Killer) ProcessState(pid int32) (string, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", err
+	}
+
+	// The command name may contain spaces and parentheses, so the state is
+	// the first field after the last ')'.
+	stat := string(data)
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return "", fmt.Errorf("malformed stat for PID %d", pid)
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) == 0 {
+		return "", fmt.Errorf("malformed stat for PID %d", pid)
+	}
+
+	return fields[0], nil
+}
@@ -0,0 +1,44 @@
+//go:build !linux
+
+package action
+
+import (
+	"fmt"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// ProcessState returns the single-letter scheduler state of a process, such
+// as "R", "S" or "Z" for a zombie.
+func (k *Killer) ProcessState(pid int32) (string, error) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return "", err
+	}
+	status, err := proc.Status()
+	if err != nil {
+		return "", err
+	}
+	if len(status) == 0 {
+		return "", fmt.Errorf("no state for PID %d", pid)
+	}
+
+	switch status[0] {
+	case process.Running:
+		return "R", nil
+	case process.Sleep:
+		return "S", nil
+	case process.Stop:
+		return "T", nil
+	case process.Idle:
+		return "I", nil
+	case process.Zombie:
+		return "Z", nil
+	case process.Wait:
+		return "W", nil
+	case process.Lock:
+		return "L", nil
+	default:
+		return status[0], nil
+	}
+}
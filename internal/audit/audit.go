@@ -0,0 +1,256 @@
+// Package audit writes an append-only trail of what logmonster observed and
+// the actions it took.
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType names an audit event.
+type EventType string
+
+// Event types.
+const (
+	ScanStarted      EventType = "scan_started"
+	FileFlagged      EventType = "file_flagged"
+	ProcessKilled    EventType = "process_killed"
+	ProcessSignalled EventType = "process_signalled"
+	ServiceStopped   EventType = "service_stopped"
+	IOLimited        EventType = "io_limited"
+	HookRun          EventType = "hook_run"
+	FileTruncated    EventType = "file_truncated"
+)
+
+// Event is one line of the audit log. Prev is the SHA-256 of the previous
+// line, chaining the events so edits or deletions can be detected.
+type Event struct {
+	Time        time.Time `json:"time"`
+	Type        EventType `json:"type"`
+	Paths       []string  `json:"paths,omitempty"`
+	Path        string    `json:"path,omitempty"`
+	GrowthBytes int64     `json:"growth_bytes,omitempty"`
+	GrowthRate  float64   `json:"growth_rate,omitempty"` // bytes per second
+	PID         int32     `json:"pid,omitempty"`
+	Unit        string    `json:"unit,omitempty"`
+	Detail      string    `json:"detail,omitempty"` // e.g. the signal or hook command
+	Error       string    `json:"error,omitempty"`  // set when the action failed
+	Prev        string    `json:"prev,omitempty"`
+}
+
+// ErrChainBroken is returned by Verify when an event's Prev does not match
+// the line before it.
+var ErrChainBroken = errors.New("audit chain broken")
+
+// maxLineSize bounds the length of one event, for Open and Verify.
+const maxLineSize = 1 << 20
+
+// Log appends events to a file, fsyncing each one. All methods are safe for
+// concurrent use, and a nil *Log discards events.
+type Log struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	f        *os.File
+	size     int64
+	prev     string
+	err      error
+	detached bool // f was rotated away from path but the new file couldn't be opened yet
+}
+
+// openFile opens the log file; tests replace it to make reopening fail.
+var openFile = os.OpenFile
+
+// Open opens or creates the audit log at path. When maxSize is positive the
+// file is rotated to path.<timestamp> before it would exceed that many bytes;
+// rotated files are never removed. The hash chain continues from the last
+// event already in the file.
+func Open(path string, maxSize int64) (*Log, error) {
+	f, size, err := openLog(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &Log{path: path, maxSize: maxSize, f: f, size: size}
+	last, err := lastLine(l.f, l.size)
+	if err != nil {
+		l.f.Close()
+		return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+	if last != nil {
+		l.prev = hash(last)
+	}
+	return l, nil
+}
+
+// openLog opens the log file at path for appending and returns its size.
+func openLog(path string) (*os.File, int64, error) {
+	f, err := openFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, err
+	}
+	return f, info.Size(), nil
+}
+
+// Record appends an event, stamping it with the current time if unset, and
+// syncs it to disk before returning. A failed rotation doesn't lose the
+// event: it is written to the current file, rotation is retried on the next
+// Record, and the failure is kept for Err.
+func (l *Log) Record(e Event) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	err := l.record(e)
+	if err != nil && l.err == nil {
+		l.err = err
+	}
+	return err
+}
+
+func (l *Log) record(e Event) error {
+	if l.f == nil {
+		return os.ErrClosed
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Prev = l.prev
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(line))+1 > l.maxSize {
+		if err := l.rotate(); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := l.f.Sync(); err != nil {
+		return err
+	}
+	l.size += int64(len(line)) + 1
+	l.prev = hash(line)
+	return nil
+}
+
+// rotate moves the current file aside and starts a new one. The current
+// handle is only swapped once the new file is open, so if either step fails
+// logging carries on in the current file, renamed or not.
+func (l *Log) rotate() error {
+	if !l.detached {
+		rotated := l.path + "." + time.Now().UTC().Format("20060102T150405.000000000Z")
+		if err := os.Rename(l.path, rotated); err != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", err)
+		}
+		l.detached = true
+	}
+
+	f, size, err := openLog(l.path)
+	if err != nil {
+		return err
+	}
+	closeErr := l.f.Close()
+	l.f, l.size, l.detached = f, size, false
+	return closeErr
+}
+
+// Err returns the first error Record has hit, so callers that can't act on
+// a failed write at the time can still surface it.
+func (l *Log) Err() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// Close closes the log file.
+func (l *Log) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// Verify checks the hash chain of an audit log and returns the number of
+// events read. The first event's Prev is trusted, so a rotated file can be
+// verified on its own.
+func Verify(r io.Reader) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	n := 0
+	var prev string
+	for sc.Scan() {
+		line := sc.Bytes()
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return n, fmt.Errorf("event %d: %w", n+1, err)
+		}
+		if n > 0 && e.Prev != prev {
+			return n, fmt.Errorf("event %d: %w", n+1, ErrChainBroken)
+		}
+		prev = hash(line)
+		n++
+	}
+	return n, sc.Err()
+}
+
+// hash returns the hex SHA-256 of an event line.
+func hash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastLine returns the last complete line of a file of the given size, or
+// nil if it is empty.
+func lastLine(f *os.File, size int64) ([]byte, error) {
+	if size == 0 {
+		return nil, nil
+	}
+	n := size
+	if n > maxLineSize {
+		n = maxLineSize
+	}
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, size-n); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if buf[len(buf)-1] == '\n' {
+		buf = buf[:len(buf)-1]
+	}
+	for i := len(buf) - 1; i >= 0; i-- {
+		if buf[i] == '\n' {
+			return buf[i+1:], nil
+		}
+	}
+	return buf, nil
+}
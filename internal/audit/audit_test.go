@@ -0,0 +1,196 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// verifyFile runs Verify on the file at path.
+func verifyFile(t *testing.T, path string) (int, error) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Verify(bytes.NewReader(data))
+}
+
+func TestLogChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := Open(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []Event{{Type: ScanStarted}, {Type: FileFlagged, Path: "/var/log/app.log"}} {
+		if err := l.Record(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening continues the chain from the last event
+	l, err = Open(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Record(Event{Type: ProcessKilled, PID: 42}); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+
+	if n, err := verifyFile(t, path); err != nil || n != 3 {
+		t.Fatalf("Verify = %d, %v; want 3 events and no error", n, err)
+	}
+
+	if err := l.Record(Event{Type: ScanStarted}); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Record after Close = %v, want %v", err, os.ErrClosed)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := Open(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"/a", "/b", "/c"} {
+		if err := l.Record(Event{Type: FileFlagged, Path: p}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+
+	tests := []struct {
+		name    string
+		content string
+		wantN   int
+	}{
+		{"edited", strings.Replace(string(data), `"/b"`, `"/x"`, 1), 2},
+		{"deleted", lines[0] + lines[2], 1},
+		{"reordered", lines[1] + lines[0] + lines[2], 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Verify(strings.NewReader(tt.content))
+			if !errors.Is(err, ErrChainBroken) {
+				t.Fatalf("Verify error = %v, want %v", err, ErrChainBroken)
+			}
+			if n != tt.wantN {
+				t.Errorf("Verify read %d events before the break, want %d", n, tt.wantN)
+			}
+		})
+	}
+
+	if _, err := Verify(strings.NewReader("not json\n")); err == nil || errors.Is(err, ErrChainBroken) {
+		t.Errorf("Verify of garbage = %v, want a decode error", err)
+	}
+}
+
+// logFiles returns the log and its rotated files in dir, oldest first.
+func logFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	rotated, err := filepath.Glob(filepath.Join(dir, "audit.log.*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(rotated)
+	return append(rotated, filepath.Join(dir, "audit.log"))
+}
+
+func TestLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(filepath.Join(dir, "audit.log"), 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := l.Record(Event{Type: FileFlagged, Path: "/var/log/app.log", GrowthBytes: int64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+
+	files := logFiles(t, dir)
+	if len(files) < 3 {
+		t.Fatalf("got %d files, want the log rotated at least twice", len(files))
+	}
+	total := 0
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > 300 {
+			t.Errorf("%s is %d bytes, over the 300 byte limit", f, info.Size())
+		}
+		n, err := verifyFile(t, f)
+		if err != nil {
+			t.Errorf("Verify(%s): %v", f, err)
+		}
+		total += n
+	}
+	if total != 10 {
+		t.Errorf("rotated files hold %d events, want 10", total)
+	}
+}
+
+func TestLogRotationReopenFails(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(filepath.Join(dir, "audit.log"), 300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	failing := func(name string, flag int, perm os.FileMode) (*os.File, error) {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EMFILE}
+	}
+	openFile = failing
+	defer func() { openFile = os.OpenFile }()
+
+	record := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if err := l.Record(Event{Type: FileFlagged, Path: "/var/log/app.log"}); err != nil {
+				t.Fatalf("Record: %v", err)
+			}
+		}
+	}
+
+	// Events keep going to the rotated-away file while reopening fails
+	record(6)
+	if !errors.Is(l.Err(), syscall.EMFILE) {
+		t.Errorf("Err = %v, want the reopen failure", l.Err())
+	}
+
+	// Once the file can be opened again, rotation completes
+	openFile = os.OpenFile
+	record(2)
+
+	total := 0
+	for _, f := range logFiles(t, dir) {
+		n, err := verifyFile(t, f)
+		if err != nil {
+			t.Errorf("Verify(%s): %v", f, err)
+		}
+		total += n
+	}
+	if total != 8 {
+		t.Errorf("log files hold %d events, want all 8", total)
+	}
+}
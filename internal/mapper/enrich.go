@@ -0,0 +1,125 @@
+package mapper
+
+import (
+	"context"
+	"sync"
+
+	"github.com/thiruk/logmonster/internal/resolver"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// pidEntry caches what was learned about one PID during enrichment.
+type pidEntry struct {
+	once    sync.Once
+	info    *types.ProcessInfo
+	service *types.ServiceInfo
+}
+
+// EnrichGrowth attributes each growing file to its writing processes, and
+// to their services when the Mapper has a resolver. Journal files are
+// attributed to systemd-journald directly. Files are resolved by up to
+// workers goroutines, each PID is looked up once even when it writes several
+// files, and results keep the order of files.
+func (m *Mapper) EnrichGrowth(ctx context.Context, files []types.FileGrowth, workers int) []types.FileAttribution {
+	if workers <= 0 {
+		workers = 4
+	}
+
+	results := make([]types.FileAttribution, len(files))
+	for i, f := range files {
+		results[i].Growth = f
+	}
+
+	var mu sync.Mutex
+	cache := make(map[int32]*pidEntry)
+	lookup := func(pid int32) *pidEntry {
+		mu.Lock()
+		entry, ok := cache[pid]
+		if !ok {
+			entry = &pidEntry{}
+			cache[pid] = entry
+		}
+		mu.Unlock()
+
+		entry.once.Do(func() {
+			info, err := m.GetProcessInfo(pid)
+			if err != nil {
+				return // Process may have exited
+			}
+			entry.info = info
+			if m.resolver != nil {
+				if svc, err := m.resolver.ResolveService(pid); err == nil {
+					entry.service = svc
+				}
+			}
+		})
+		return entry
+	}
+
+	// Journal files are attributed to journald without looking for writers
+	var journalOnce sync.Once
+	var journal *types.ServiceInfo
+	journalService := func() *types.ServiceInfo {
+		journalOnce.Do(func() { journal = m.journalService() })
+		return journal
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if resolver.IsJournalFile(files[i].Path) {
+					svc := journalService()
+					if svc.MainPID > 0 {
+						if entry := lookup(svc.MainPID); entry.info != nil {
+							proc := *entry.info
+							proc.Unit = svc.Unit
+							results[i].Processes = append(results[i].Processes, proc)
+						}
+					}
+					results[i].Services = append(results[i].Services, *svc)
+					continue
+				}
+
+				pids, err := m.findPIDs(files[i].Path)
+				if err != nil {
+					continue
+				}
+
+				seen := make(map[string]bool)
+				for _, pid := range pids {
+					entry := lookup(pid)
+					if entry.info == nil {
+						continue
+					}
+					proc := *entry.info
+					if entry.service != nil {
+						proc.Unit = entry.service.Unit
+					}
+					results[i].Processes = append(results[i].Processes, proc)
+					if entry.service != nil && !seen[entry.service.Unit] {
+						seen[entry.service.Unit] = true
+						results[i].Services = append(results[i].Services, *entry.service)
+					}
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := range files {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
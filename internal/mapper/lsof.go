@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"errors"
+	"os/exec"
+	"sync"
+)
+
+// ErrNoLsof is returned by lsof-based lookups when lsof isn't installed.
+var ErrNoLsof = errors.New("lsof not found on PATH")
+
+// lsof caches the location of the lsof binary, so hosts without it skip
+// straight to the fallback instead of failing a fork/exec on every lookup.
+var lsof struct {
+	once sync.Once
+	path string
+}
+
+// lsofPath returns the path of lsof, or ErrNoLsof. PATH is searched once
+// per process.
+func lsofPath() (string, error) {
+	lsof.once.Do(func() {
+		lsof.path, _ = exec.LookPath("lsof")
+	})
+	if lsof.path == "" {
+		return "", ErrNoLsof
+	}
+	return lsof.path, nil
+}
+
+// LsofAvailable reports whether lsof is installed. Without it, process
+// mapping uses the slower /proc scan on Linux and finds nothing elsewhere.
+func LsofAvailable() bool {
+	_, err := lsofPath()
+	return err == nil
+}
@@ -4,39 +4,36 @@ package mapper
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/process"
+	"github.com/thiruk/logmonster/internal/resolver"
 	"github.com/thiruk/logmonster/pkg/types"
 )
 
 // Mapper maps files to processes.
-type Mapper struct{}
+type Mapper struct {
+	resolver *resolver.Resolver
+}
 
 // New creates a new Mapper.
 func New() *Mapper {
 	return &Mapper{}
 }
 
+// NewWithResolver creates a Mapper that can also resolve writers to services.
+func NewWithResolver(r *resolver.Resolver) *Mapper {
+	return &Mapper{resolver: r}
+}
+
 // FindProcessForFile finds the process(es) writing to a file.
 func (m *Mapper) FindProcessForFile(filePath string) ([]types.ProcessInfo, error) {
-	// Try lsof first
-	pids, err := m.findPIDsWithLsof(filePath)
-	if err != nil || len(pids) == 0 {
-		// Try /proc fallback
-		pids, err = m.findPIDsFromProc(filePath)
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	if len(pids) == 0 {
-		return nil, fmt.Errorf("no process found with file open: %s", filePath)
+	pids, err := m.findPIDs(filePath)
+	if err != nil {
+		return nil, err
 	}
 
 	var processes []types.ProcessInfo
@@ -51,73 +48,98 @@ func (m *Mapper) FindProcessForFile(filePath string) ([]types.ProcessInfo, error
 	return processes, nil
 }
 
-// findPIDsWithLsof uses lsof to find PIDs with a file open.
-func (m *Mapper) findPIDsWithLsof(filePath string) ([]int32, error) {
-	cmd := exec.Command("lsof", "-t", filePath)
-	output, err := cmd.Output()
+// findPIDs finds the PIDs with a file open, trying lsof first.
+func (m *Mapper) findPIDs(filePath string) ([]int32, error) {
+	pids, err := m.findPIDsWithLsof(filePath)
+	if err != nil || len(pids) == 0 {
+		// Try platform fallback
+		pids, err = m.findPIDsFallback(filePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(pids) == 0 {
+		return nil, fmt.Errorf("no process found with file open: %s", filePath)
+	}
+
+	return pids, nil
+}
+
+// FindServiceForFile finds the services whose processes are writing to a file.
+// Results are deduplicated by unit name. Journal files are attributed to
+// journald, as in EnrichGrowth.
+func (m *Mapper) FindServiceForFile(filePath string) ([]types.ServiceInfo, error) {
+	if resolver.IsJournalFile(filePath) {
+		return []types.ServiceInfo{*m.journalService()}, nil
+	}
+
+	if m.resolver == nil {
+		return nil, fmt.Errorf("mapper has no resolver configured")
+	}
+
+	processes, err := m.FindProcessForFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 
-	var pids []int32
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue
-		}
-		pid, err := strconv.ParseInt(line, 10, 32)
+	seen := make(map[string]bool)
+	var services []types.ServiceInfo
+	for _, proc := range processes {
+		info, err := m.resolver.ResolveService(proc.PID)
 		if err != nil {
+			continue // Not every writer belongs to a service
+		}
+		if seen[info.Unit] {
 			continue
 		}
-		pids = append(pids, int32(pid))
+		seen[info.Unit] = true
+		services = append(services, *info)
 	}
 
-	return pids, nil
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no service found writing to file: %s", filePath)
+	}
+
+	return services, nil
+}
+
+// journalService returns the service that owns journal files: journald as
+// systemd reports it, or a fallback entry when it can't be resolved.
+func (m *Mapper) journalService() *types.ServiceInfo {
+	if m.resolver != nil {
+		if svc, err := m.resolver.ResolveUnit(resolver.JournaldUnit); err == nil {
+			return svc
+		}
+	}
+	return &types.ServiceInfo{Unit: resolver.JournaldUnit, Status: resolver.FallbackStatus}
 }
 
-// findPIDsFromProc searches /proc for processes with the file open.
-func (m *Mapper) findPIDsFromProc(filePath string) ([]int32, error) {
-	absPath, err := filepath.Abs(filePath)
+// findPIDsWithLsof uses lsof to find PIDs with a file open.
+func (m *Mapper) findPIDsWithLsof(filePath string) ([]int32, error) {
+	path, err := lsofPath()
 	if err != nil {
 		return nil, err
 	}
 
-	var pids []int32
-
-	// Read all /proc entries
-	entries, err := os.ReadDir("/proc")
+	cmd := exec.Command(path, "-t", filePath)
+	output, err := cmd.Output()
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
+	var pids []int32
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
 			continue
 		}
-
-		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
-		if err != nil {
-			continue // Not a PID directory
-		}
-
-		// Check fd directory
-		fdDir := filepath.Join("/proc", entry.Name(), "fd")
-		fds, err := os.ReadDir(fdDir)
+		pid, err := strconv.ParseInt(line, 10, 32)
 		if err != nil {
-			continue // Permission denied or process exited
-		}
-
-		for _, fd := range fds {
-			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
-			if err != nil {
-				continue
-			}
-			if link == absPath {
-				pids = append(pids, int32(pid))
-				break
-			}
+			continue
 		}
+		pids = append(pids, int32(pid))
 	}
 
 	return pids, nil
@@ -143,41 +165,28 @@ func (m *Mapper) GetProcessInfo(pid int32) (*types.ProcessInfo, error) {
 		memoryMB = float64(memInfo.RSS) / (1024 * 1024)
 	}
 
-	// Get write bytes from /proc/[pid]/io
+	// Get write bytes from /proc/[pid]/io. Writes whose dirty pages were
+	// dropped before writeback never reached the disk.
 	writeBytes := m.getWriteBytes(pid)
+	diskWriteBytes := writeBytes - m.GetCancelledWriteBytes(pid)
+	if diskWriteBytes < 0 {
+		diskWriteBytes = 0
+	}
 
-	startTime := time.Unix(createTime/1000, 0)
+	// Keep millisecond precision so the kill path can detect PID reuse
+	startTime := time.UnixMilli(createTime)
 
 	return &types.ProcessInfo{
-		PID:        pid,
-		Name:       name,
-		Cmdline:    cmdline,
-		Exe:        exe,
-		User:       username,
-		StartTime:  startTime,
-		CPUPercent: cpuPercent,
-		MemoryMB:   memoryMB,
-		WriteBytes: writeBytes,
+		PID:            pid,
+		Name:           name,
+		Cmdline:        cmdline,
+		Exe:            exe,
+		User:           username,
+		StartTime:      startTime,
+		CPUPercent:     cpuPercent,
+		MemoryMB:       memoryMB,
+		WriteBytes:     writeBytes,
+		DiskWriteBytes: diskWriteBytes,
+		BlockIODelay:   m.getBlockIODelay(pid),
 	}, nil
 }
-
-// getWriteBytes reads write_bytes from /proc/[pid]/io.
-func (m *Mapper) getWriteBytes(pid int32) int64 {
-	path := fmt.Sprintf("/proc/%d/io", pid)
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return 0
-	}
-
-	for _, line := range strings.Split(string(data), "\n") {
-		if strings.HasPrefix(line, "write_bytes:") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				bytes, _ := strconv.ParseInt(parts[1], 10, 64)
-				return bytes
-			}
-		}
-	}
-
-	return 0
-}
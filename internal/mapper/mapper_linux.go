@@ -0,0 +1,173 @@
+//go:build linux
+
+package mapper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thiruk/logmonster/internal/procstat"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// deletedSuffix is appended by the kernel to fd links of unlinked files.
+const deletedSuffix = " (deleted)"
+
+// findPIDsFallback finds PIDs with a file open when lsof is unavailable.
+func (m *Mapper) findPIDsFallback(filePath string) ([]int32, error) {
+	return m.findPIDsFromProc(filePath)
+}
+
+// findPIDsFromProc searches /proc for processes with the file open.
+func (m *Mapper) findPIDsFromProc(filePath string) ([]int32, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int32
+
+	// Read all /proc entries
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		pid, err := strconv.ParseInt(entry.Name(), 10, 32)
+		if err != nil {
+			continue // Not a PID directory
+		}
+
+		// Check fd directory
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // Permission denied or process exited
+		}
+
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			if link == absPath {
+				pids = append(pids, int32(pid))
+				break
+			}
+		}
+	}
+
+	return pids, nil
+}
+
+// getWriteBytes reads write_bytes from /proc/[pid]/io.
+func (m *Mapper) getWriteBytes(pid int32) int64 {
+	return readProcIO(pid, "write_bytes")
+}
+
+// GetCancelledWriteBytes reads cancelled_write_bytes from /proc/[pid]/io:
+// bytes counted in write_bytes whose dirty pages were truncated or deleted
+// before they reached disk.
+func (m *Mapper) GetCancelledWriteBytes(pid int32) int64 {
+	return readProcIO(pid, "cancelled_write_bytes")
+}
+
+// readProcIO returns one counter from /proc/[pid]/io, or 0 if the file or
+// the field can't be read.
+func readProcIO(pid int32, field string) int64 {
+	path := fmt.Sprintf("/proc/%d/io", pid)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+
+	prefix := field + ":"
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			parts := strings.Fields(line)
+			if len(parts) >= 2 {
+				bytes, _ := strconv.ParseInt(parts[1], 10, 64)
+				return bytes
+			}
+		}
+	}
+
+	return 0
+}
+
+// userHZ is the clock tick rate of the tick counts in /proc/[pid]/stat. It is
+// 100 on every mainstream architecture.
+const userHZ = 100
+
+// blkioTicksField is the position of delayacct_blkio_ticks in
+// /proc/[pid]/stat, counting from 1 as proc(5) does.
+const blkioTicksField = 42
+
+// getBlockIODelay returns how long a process has spent waiting for block
+// I/O, from delay accounting. It is 0 when delay accounting is disabled
+// (the delayacct boot option or kernel.task_delayacct sysctl).
+func (m *Mapper) getBlockIODelay(pid int32) time.Duration {
+	stat, err := procstat.Read(pid)
+	if err != nil {
+		return 0
+	}
+	ticks, err := stat.Field(blkioTicksField)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(ticks) * time.Second / userHZ
+}
+
+// GetOpenFiles lists the regular files a process has open, sorted by size
+// descending. Files that were deleted while open are marked as such.
+func (m *Mapper) GetOpenFiles(pid int32) ([]types.OpenFile, error) {
+	fdDir := fmt.Sprintf("/proc/%d/fd", pid)
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read open files for PID %d: %w", pid, err)
+	}
+
+	var files []types.OpenFile
+	for _, fd := range fds {
+		num, err := strconv.Atoi(fd.Name())
+		if err != nil {
+			continue
+		}
+
+		fdPath := filepath.Join(fdDir, fd.Name())
+		link, err := os.Readlink(fdPath)
+		if err != nil || !strings.HasPrefix(link, "/") {
+			continue // Sockets, pipes and anonymous inodes
+		}
+
+		// Stat through the fd link so deleted files still resolve
+		info, err := os.Stat(fdPath)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		deleted := strings.HasSuffix(link, deletedSuffix)
+		files = append(files, types.OpenFile{
+			FD:      num,
+			Path:    strings.TrimSuffix(link, deletedSuffix),
+			Size:    info.Size(),
+			Deleted: deleted,
+		})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Size > files[j].Size
+	})
+
+	return files, nil
+}
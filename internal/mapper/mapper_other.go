@@ -0,0 +1,46 @@
+//go:build !linux
+
+package mapper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// findPIDsFallback finds nothing: without /proc there is no way to find
+// the processes holding a file open other than lsof, which already ran.
+func (m *Mapper) findPIDsFallback(filePath string) ([]int32, error) {
+	return nil, nil
+}
+
+// getWriteBytes returns the bytes written by a process, or 0 if the
+// platform does not expose per-process I/O counters.
+func (m *Mapper) getWriteBytes(pid int32) int64 {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return 0
+	}
+	io, err := proc.IOCounters()
+	if err != nil || io == nil {
+		return 0
+	}
+	return int64(io.WriteBytes)
+}
+
+// GetCancelledWriteBytes requires /proc and returns 0 off Linux.
+func (m *Mapper) GetCancelledWriteBytes(pid int32) int64 {
+	return 0
+}
+
+// getBlockIODelay requires Linux delay accounting and returns 0 elsewhere.
+func (m *Mapper) getBlockIODelay(pid int32) time.Duration {
+	return 0
+}
+
+// GetOpenFiles requires /proc and is only supported on Linux.
+func (m *Mapper) GetOpenFiles(pid int32) ([]types.OpenFile, error) {
+	return nil, fmt.Errorf("listing open files is only supported on Linux")
+}
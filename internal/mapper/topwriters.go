@@ -0,0 +1,66 @@
+package mapper
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// TopWriters samples every process's cumulative write bytes twice, interval
+// apart, and returns the n processes with the highest write rate. Processes
+// that wrote nothing or exited during the interval are omitted.
+func (m *Mapper) TopWriters(ctx context.Context, interval time.Duration, n int) ([]types.ProcessInfo, error) {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	before := make(map[int32]int64, len(pids))
+	for _, pid := range pids {
+		before[pid] = m.getWriteBytes(pid)
+	}
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(interval):
+	}
+
+	elapsed := time.Since(start).Seconds()
+
+	type sample struct {
+		pid  int32
+		rate float64
+	}
+	var samples []sample
+	for pid, b1 := range before {
+		delta := m.getWriteBytes(pid) - b1
+		if delta <= 0 {
+			continue
+		}
+		samples = append(samples, sample{pid: pid, rate: float64(delta) / elapsed})
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].rate > samples[j].rate
+	})
+
+	var top []types.ProcessInfo
+	for _, s := range samples {
+		if n > 0 && len(top) >= n {
+			break
+		}
+		info, err := m.GetProcessInfo(s.pid)
+		if err != nil {
+			continue // Process exited
+		}
+		info.WriteRate = s.rate
+		top = append(top, *info)
+	}
+
+	return top, nil
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+	"github.com/thiruk/logmonster/pkg/util"
+)
+
+// noExtension is the bucket name for files without an extension.
+const noExtension = "(none)"
+
+// AggregateByExtension buckets growing files by extension, summing growth
+// and rate per bucket, sorted by growth rate descending.
+func AggregateByExtension(files []types.FileGrowth) []types.ExtGrowth {
+	buckets := make(map[string]*types.ExtGrowth)
+
+	for _, f := range files {
+		ext := strings.ToLower(filepath.Ext(f.Path))
+		if ext == "" {
+			ext = noExtension
+		}
+
+		bucket, ok := buckets[ext]
+		if !ok {
+			bucket = &types.ExtGrowth{Extension: ext}
+			buckets[ext] = bucket
+		}
+		bucket.FileCount++
+		bucket.GrowthBytes += f.GrowthBytes
+		bucket.GrowthRate += f.GrowthRate
+	}
+
+	result := make([]types.ExtGrowth, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GrowthRate > result[j].GrowthRate
+	})
+
+	return result
+}
+
+// RenderExtensionTable renders a table of growth aggregated by extension.
+func RenderExtensionTable(exts []types.ExtGrowth) string {
+	table := NewTable("EXT", "FILES", "GROWTH", "GROWTH/SEC")
+
+	for _, e := range exts {
+		table.AddRow(
+			e.Extension,
+			fmt.Sprintf("%d", e.FileCount),
+			util.FormatBytesWithSign(e.GrowthBytes),
+			util.FormatRate(e.GrowthRate),
+		)
+	}
+
+	return table.Render()
+}
+
+// RenderLogicalTable renders the output of logical logs followed across
+// rotation.
+func RenderLogicalTable(logs []types.LogicalGrowth) string {
+	table := NewTable("LOG", "FILES", "GROWTH", "GROWTH/SEC", "TOTAL", "ROTATIONS")
+
+	for _, l := range logs {
+		table.AddRow(
+			truncatePath(filepath.Join(l.Dir, l.Pattern), 40),
+			fmt.Sprintf("%d", l.Files),
+			util.FormatBytesWithSign(l.GrowthBytes),
+			fmt.Sprintf("%s %s", GetSeverityEmoji(l.GrowthRate), util.FormatRate(l.GrowthRate)),
+			util.FormatBytes(l.TotalBytes),
+			fmt.Sprintf("%d", l.Rotations),
+		)
+	}
+
+	return table.Render()
+}
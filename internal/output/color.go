@@ -3,6 +3,7 @@ package output
 
 import (
 	"github.com/charmbracelet/lipgloss"
+	"github.com/thiruk/logmonster/pkg/types"
 )
 
 // Color definitions for severity levels.
@@ -22,28 +23,28 @@ const (
 	EmojiRed    = "🔴"
 )
 
+// activeScheme is the severity scheme used for colors and emoji.
+var activeScheme = types.DefaultSeverityScheme()
+
+// SetSeverityScheme sets the severity scheme used for colors and emoji.
+func SetSeverityScheme(scheme types.SeverityScheme) {
+	if len(scheme.Tiers) == 0 {
+		scheme = types.DefaultSeverityScheme()
+	}
+	activeScheme = scheme
+}
+
+// ActiveSeverityScheme returns the severity scheme currently in use.
+func ActiveSeverityScheme() types.SeverityScheme {
+	return activeScheme
+}
+
 // GetSeverityColor returns the color for a given write rate (bytes/sec).
 func GetSeverityColor(bytesPerSec float64) lipgloss.Color {
-	mbPerSec := bytesPerSec / (1024 * 1024)
-	switch {
-	case mbPerSec >= 10:
-		return ColorRed
-	case mbPerSec >= 1:
-		return ColorYellow
-	default:
-		return ColorGreen
-	}
+	return lipgloss.Color(activeScheme.Tier(bytesPerSec).Color)
 }
 
 // GetSeverityEmoji returns the emoji for a given write rate (bytes/sec).
 func GetSeverityEmoji(bytesPerSec float64) string {
-	mbPerSec := bytesPerSec / (1024 * 1024)
-	switch {
-	case mbPerSec >= 10:
-		return EmojiRed
-	case mbPerSec >= 1:
-		return EmojiYellow
-	default:
-		return EmojiGreen
-	}
+	return activeScheme.Tier(bytesPerSec).Emoji
 }
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+	"github.com/thiruk/logmonster/pkg/util"
+	"golang.org/x/term"
+)
+
+// defaultTermWidth is used when the terminal width can't be detected.
+const defaultTermWidth = 80
+
+// minCompactPathWidth keeps some of the path visible on very narrow widths.
+const minCompactPathWidth = 12
+
+// RenderGrowthCompact renders growing files one per line in the active sort
+// order, truncating paths to fit width. A width of zero or less uses the terminal width.
+func RenderGrowthCompact(files []types.FileGrowth, width int) string {
+	if width <= 0 {
+		width = terminalWidth()
+	}
+	files = sortedForDisplay(files)
+
+	header, labels := pathLabels(files)
+
+	var sb strings.Builder
+	sb.WriteString(header)
+	for i, f := range files {
+		prefix := fmt.Sprintf("%s %-10s %-9s ",
+			GetSeverityEmoji(f.GrowthRate),
+			util.FormatRate(f.GrowthRate),
+			util.FormatBytesWithSign(f.GrowthBytes),
+		)
+		pathWidth := width - displayWidth(prefix)
+		if pathWidth < minCompactPathWidth {
+			pathWidth = minCompactPathWidth
+		}
+		sb.WriteString(prefix)
+		sb.WriteString(growthPath(f, labels[i], pathWidth))
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// RenderSummaryLine condenses a scan result into one line, e.g.
+// "3 files growing · +127.0 MB in 5s · fastest: app.log @ 12.0 MB/s 🔴".
+func RenderSummaryLine(result *types.ScanResult) string {
+	window := result.Interval.Round(time.Millisecond)
+	if len(result.GrowingFiles) == 0 {
+		return fmt.Sprintf("no files growing in %s", window)
+	}
+
+	fastest := result.GrowingFiles[0]
+	for _, f := range result.GrowingFiles[1:] {
+		if f.GrowthRate > fastest.GrowthRate {
+			fastest = f
+		}
+	}
+
+	noun := "files"
+	if len(result.GrowingFiles) == 1 {
+		noun = "file"
+	}
+
+	return fmt.Sprintf("%d %s growing · %s in %s · fastest: %s @ %s %s",
+		len(result.GrowingFiles), noun,
+		util.FormatBytesWithSign(result.TotalGrowth), window,
+		filepath.Base(fastest.Path), util.FormatRate(fastest.GrowthRate),
+		GetSeverityEmoji(fastest.GrowthRate),
+	)
+}
+
+// terminalWidth returns the width of the terminal on stdout.
+func terminalWidth() int {
+	w, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || w <= 0 {
+		return defaultTermWidth
+	}
+	return w
+}
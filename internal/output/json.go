@@ -0,0 +1,124 @@
+package output
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// processJSON is the JSON form of a process.
+type processJSON struct {
+	PID        int32     `json:"pid"`
+	Name       string    `json:"name"`
+	Cmdline    string    `json:"cmdline,omitempty"`
+	Exe        string    `json:"exe,omitempty"`
+	User       string    `json:"user,omitempty"`
+	StartTime  time.Time `json:"start_time"`
+	CPUPercent float64   `json:"cpu_percent"`
+	MemoryMB   float64   `json:"memory_mb"`
+	WriteBytes int64     `json:"write_bytes"`
+	WriteRate  float64   `json:"write_rate,omitempty"` // bytes per second
+	Unit       string    `json:"unit,omitempty"`
+
+	DiskWriteBytes int64 `json:"disk_write_bytes"`
+	BlockIODelayMS int64 `json:"block_io_delay_ms,omitempty"`
+}
+
+// serviceJSON is the JSON form of a systemd service.
+type serviceJSON struct {
+	Unit        string    `json:"unit"`
+	Status      string    `json:"status,omitempty"`
+	MainPID     int32     `json:"main_pid,omitempty"`
+	StartTime   time.Time `json:"start_time"`
+	Description string    `json:"description,omitempty"`
+}
+
+// attributionJSON is the JSON form of a growing file and its writers.
+type attributionJSON struct {
+	Path        string        `json:"path"`
+	InitialSize int64         `json:"initial_size"`
+	FinalSize   int64         `json:"final_size"`
+	GrowthBytes int64         `json:"growth_bytes"`
+	GrowthRate  float64       `json:"growth_rate"`               // bytes per second
+	NormRate    float64       `json:"normalized_rate,omitempty"` // bytes per configured interval
+	Severity    string        `json:"severity"`
+	Reason      string        `json:"reason,omitempty"`
+	Processes   []processJSON `json:"processes"`
+	Services    []serviceJSON `json:"services"`
+}
+
+// RenderProcessJSON renders process information as JSON.
+func RenderProcessJSON(info types.ProcessInfo) ([]byte, error) {
+	return json.MarshalIndent(newProcessJSON(info), "", "  ")
+}
+
+// RenderAttributionJSON renders growing files with the processes writing to
+// them and the services those processes belong to.
+func RenderAttributionJSON(attrs []types.FileAttribution) ([]byte, error) {
+	docs := make([]attributionJSON, 0, len(attrs))
+	for _, a := range attrs {
+		doc := attributionJSON{
+			Path:        a.Growth.Path,
+			InitialSize: a.Growth.InitialSize,
+			FinalSize:   a.Growth.FinalSize,
+			GrowthBytes: a.Growth.GrowthBytes,
+			GrowthRate:  a.Growth.GrowthRate,
+			NormRate:    a.Growth.NormalizedRate,
+			Severity:    ActiveSeverityScheme().Tier(a.Growth.GrowthRate).Name,
+			Reason:      a.Growth.Reason,
+			Processes:   make([]processJSON, 0, len(a.Processes)),
+			Services:    make([]serviceJSON, 0, len(a.Services)),
+		}
+		for _, p := range a.Processes {
+			doc.Processes = append(doc.Processes, newProcessJSON(p))
+		}
+		for _, s := range a.Services {
+			doc.Services = append(doc.Services, newServiceJSON(s))
+		}
+		docs = append(docs, doc)
+	}
+	return json.MarshalIndent(docs, "", "  ")
+}
+
+// RenderJSONGzip streams a scan result to w as gzip-compressed JSON. The
+// gzip stream is closed before returning, so w holds a complete archive.
+func RenderJSONGzip(result *types.ScanResult, w io.Writer) error {
+	zw := gzip.NewWriter(w)
+	if err := json.NewEncoder(zw).Encode(result); err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+func newProcessJSON(info types.ProcessInfo) processJSON {
+	return processJSON{
+		PID:        info.PID,
+		Name:       info.Name,
+		Cmdline:    info.Cmdline,
+		Exe:        info.Exe,
+		User:       info.User,
+		StartTime:  zone(info.StartTime),
+		CPUPercent: info.CPUPercent,
+		MemoryMB:   info.MemoryMB,
+		WriteBytes: info.WriteBytes,
+		WriteRate:  info.WriteRate,
+		Unit:       info.Unit,
+
+		DiskWriteBytes: info.DiskWriteBytes,
+		BlockIODelayMS: info.BlockIODelay.Milliseconds(),
+	}
+}
+
+func newServiceJSON(info types.ServiceInfo) serviceJSON {
+	return serviceJSON{
+		Unit:        info.Unit,
+		Status:      info.Status,
+		MainPID:     info.MainPID,
+		StartTime:   zone(info.StartTime),
+		Description: info.Description,
+	}
+}
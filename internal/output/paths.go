@@ -0,0 +1,131 @@
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// PathDisplay selects how file paths are shown in growth output.
+type PathDisplay int
+
+// Path display modes.
+const (
+	PathAbsolute PathDisplay = iota // full paths
+	PathRelative                    // relative to the common directory of the result set
+	PathBasename                    // base names, disambiguated on collision
+)
+
+// ParsePathDisplay parses "absolute", "relative" or "basename". An empty
+// string selects PathAbsolute.
+func ParsePathDisplay(s string) (PathDisplay, error) {
+	switch s {
+	case "", "absolute":
+		return PathAbsolute, nil
+	case "relative":
+		return PathRelative, nil
+	case "basename":
+		return PathBasename, nil
+	default:
+		return PathAbsolute, fmt.Errorf("unknown path display %q", s)
+	}
+}
+
+// activePathDisplay is the path display mode used by growth renderers.
+var activePathDisplay = PathAbsolute
+
+// SetPathDisplay sets how growth renderers display file paths.
+func SetPathDisplay(d PathDisplay) {
+	activePathDisplay = d
+}
+
+// ActivePathDisplay returns the path display mode currently in use.
+func ActivePathDisplay() PathDisplay {
+	return activePathDisplay
+}
+
+// pathLabels returns the display label for each file under the active path
+// display mode, plus a header line naming any stripped prefix.
+func pathLabels(files []types.FileGrowth) (header string, labels []string) {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.Path
+	}
+
+	switch activePathDisplay {
+	case PathRelative:
+		prefix := commonDir(paths)
+		if prefix == "" || prefix == string(filepath.Separator) {
+			return "", paths
+		}
+		labels = make([]string, len(paths))
+		for i, p := range paths {
+			labels[i] = strings.TrimPrefix(p, prefix+string(filepath.Separator))
+		}
+		return fmt.Sprintf("under %s%c...\n", prefix, filepath.Separator), labels
+	case PathBasename:
+		return "", uniqueBasenames(paths)
+	default:
+		return "", paths
+	}
+}
+
+// commonDir returns the deepest directory containing every path.
+func commonDir(paths []string) string {
+	if len(paths) == 0 {
+		return ""
+	}
+
+	prefix := filepath.Dir(paths[0])
+	for _, p := range paths[1:] {
+		for !strings.HasPrefix(p, prefix+string(filepath.Separator)) {
+			parent := filepath.Dir(prefix)
+			if parent == prefix {
+				return ""
+			}
+			prefix = parent
+		}
+	}
+	return prefix
+}
+
+// uniqueBasenames returns each path's base name. Colliding names get as many
+// trailing parent directories appended as needed to tell them apart, as in
+// "app.log (api)" and "app.log (worker)".
+func uniqueBasenames(paths []string) []string {
+	labels := make([]string, len(paths))
+	depth := make([]int, len(paths))
+	for i, p := range paths {
+		labels[i] = filepath.Base(p)
+	}
+
+	for {
+		groups := make(map[string][]int)
+		for i, l := range labels {
+			groups[l] = append(groups[l], i)
+		}
+
+		changed := false
+		for _, idx := range groups {
+			if len(idx) < 2 {
+				continue
+			}
+			for _, i := range idx {
+				dir := filepath.Dir(paths[i])
+				parts := strings.Split(strings.Trim(dir, string(filepath.Separator)), string(filepath.Separator))
+				if depth[i] >= len(parts) {
+					continue // Identical paths can't be told apart
+				}
+				depth[i]++
+				suffix := filepath.Join(parts[len(parts)-depth[i]:]...)
+				labels[i] = fmt.Sprintf("%s (%s)", filepath.Base(paths[i]), suffix)
+				changed = true
+			}
+		}
+		if !changed {
+			return labels
+		}
+	}
+}
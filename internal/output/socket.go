@@ -0,0 +1,153 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// socketWriteTimeout bounds how long a slow consumer can stall a write.
+const socketWriteTimeout = 5 * time.Second
+
+// Sink streams scan results to a Unix domain socket as newline-delimited
+// JSON, one ScanResult per line. It is safe for concurrent use.
+type Sink struct {
+	addr string
+
+	mu       sync.Mutex
+	conn     net.Conn          // dial mode
+	listener net.Listener      // listen mode
+	clients  map[net.Conn]bool // listen mode
+	closed   bool
+}
+
+// SocketSink returns a Sink that dials the Unix socket at addr, for a
+// collector that is already listening. The connection is made on the first
+// Write and re-made after a failure, so the collector may restart freely.
+func SocketSink(addr string) *Sink {
+	return &Sink{addr: addr}
+}
+
+// ListenSocketSink returns a Sink that listens on the Unix socket at addr
+// and sends each result to every connected client. A stale socket file
+// left by a previous run is replaced.
+func ListenSocketSink(addr string) (*Sink, error) {
+	if info, err := os.Lstat(addr); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(addr)
+	}
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{addr: addr, listener: l, clients: make(map[net.Conn]bool)}
+	go s.accept()
+	return s, nil
+}
+
+// accept adds clients until the listener is closed.
+func (s *Sink) accept() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		if s.closed {
+			conn.Close()
+		} else {
+			s.clients[conn] = true
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Write sends one scan result. In dial mode a failed write is retried once
+// on a fresh connection; if that fails too the error is returned and the
+// next Write tries again. In listen mode clients that fail are dropped, and
+// having no clients is not an error.
+func (s *Sink) Write(result *types.ScanResult) error {
+	line, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return net.ErrClosed
+	}
+	if s.listener != nil {
+		for conn := range s.clients {
+			if err := writeLine(conn, line); err != nil {
+				conn.Close()
+				delete(s.clients, conn)
+			}
+		}
+		return nil
+	}
+
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			conn, err := net.DialTimeout("unix", s.addr, socketWriteTimeout)
+			if err != nil {
+				return err
+			}
+			s.conn = conn
+		}
+		err := writeLine(s.conn, line)
+		if err == nil {
+			return nil
+		}
+		s.conn.Close()
+		s.conn = nil
+		if attempt > 0 {
+			return err
+		}
+	}
+}
+
+// writeLine writes line to conn within socketWriteTimeout.
+func writeLine(conn net.Conn, line []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := conn.Write(line)
+	return err
+}
+
+// Close closes the connection, or the listener, its clients and the socket
+// file.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	if s.listener == nil {
+		if s.conn == nil {
+			return nil
+		}
+		return s.conn.Close()
+	}
+
+	err := s.listener.Close()
+	for conn := range s.clients {
+		conn.Close()
+	}
+	s.clients = nil
+	if rmErr := os.Remove(s.addr); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) && err == nil {
+		err = rmErr
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Growth sort keys accepted by SortGrowth.
+const (
+	SortByRate      = "rate"
+	SortByBytes     = "bytes"
+	SortByPath      = "path"
+	SortByFinalSize = "final_size"
+)
+
+// growthLess compares two files by a sort key in ascending order.
+var growthLess = map[string]func(a, b types.FileGrowth) bool{
+	SortByRate:      func(a, b types.FileGrowth) bool { return a.GrowthRate < b.GrowthRate },
+	SortByBytes:     func(a, b types.FileGrowth) bool { return a.GrowthBytes < b.GrowthBytes },
+	SortByPath:      func(a, b types.FileGrowth) bool { return a.Path < b.Path },
+	SortByFinalSize: func(a, b types.FileGrowth) bool { return a.FinalSize < b.FinalSize },
+}
+
+// SortGrowth sorts files in place by key: "rate", "bytes", "path" or
+// "final_size". Ties are broken by path so the order is deterministic.
+func SortGrowth(files []types.FileGrowth, key string, ascending bool) error {
+	less, ok := growthLess[key]
+	if !ok {
+		return fmt.Errorf("unknown sort key %q (want %s)", key, strings.Join(sortKeys(), ", "))
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if !ascending {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	return nil
+}
+
+// sortKeys returns the supported sort keys in a fixed order.
+func sortKeys() []string {
+	return []string{SortByRate, SortByBytes, SortByPath, SortByFinalSize}
+}
+
+// GrowthSort is a sort spec for growth renderers.
+type GrowthSort struct {
+	Key       string
+	Ascending bool
+}
+
+// activeGrowthSort is the order used by growth renderers. The zero value
+// keeps the order files were passed in, which is rate descending.
+var activeGrowthSort GrowthSort
+
+// SetGrowthSort sets the order growth renderers display files in. An empty
+// key keeps the order the files were passed in.
+func SetGrowthSort(spec GrowthSort) error {
+	if spec.Key != "" {
+		if _, ok := growthLess[spec.Key]; !ok {
+			return fmt.Errorf("unknown sort key %q (want %s)", spec.Key, strings.Join(sortKeys(), ", "))
+		}
+	}
+	activeGrowthSort = spec
+	return nil
+}
+
+// ActiveGrowthSort returns the sort spec currently in use.
+func ActiveGrowthSort() GrowthSort {
+	return activeGrowthSort
+}
+
+// sortedForDisplay returns files in the active sort order, leaving the
+// caller's slice untouched.
+func sortedForDisplay(files []types.FileGrowth) []types.FileGrowth {
+	if activeGrowthSort.Key == "" {
+		return files
+	}
+	sorted := append([]types.FileGrowth(nil), files...)
+	SortGrowth(sorted, activeGrowthSort.Key, activeGrowthSort.Ascending)
+	return sorted
+}
@@ -2,9 +2,15 @@ package output
 
 import (
 	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 	"github.com/thiruk/logmonster/pkg/types"
 	"github.com/thiruk/logmonster/pkg/util"
 )
@@ -46,6 +52,9 @@ var (
 			Foreground(ColorGreen)
 )
 
+// ansiPattern matches ANSI escape sequences such as lipgloss colors.
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
 // Table represents a formatted table for terminal output.
 type Table struct {
 	headers []string
@@ -57,7 +66,7 @@ type Table struct {
 func NewTable(headers ...string) *Table {
 	widths := make([]int, len(headers))
 	for i, h := range headers {
-		widths[i] = len(h)
+		widths[i] = displayWidth(h)
 	}
 	return &Table{
 		headers: headers,
@@ -72,8 +81,8 @@ func (t *Table) AddRow(cells ...string) {
 	for i := range row {
 		if i < len(cells) {
 			row[i] = cells[i]
-			if len(cells[i]) > t.widths[i] {
-				t.widths[i] = len(cells[i])
+			if w := displayWidth(cells[i]); w > t.widths[i] {
+				t.widths[i] = w
 			}
 		}
 	}
@@ -138,53 +147,453 @@ func (t *Table) Render() string {
 }
 
 func padRight(s string, width int) string {
-	if len(s) >= width {
+	n := displayWidth(s)
+	if n >= width {
 		return s
 	}
-	return s + strings.Repeat(" ", width-len(s))
+	return s + strings.Repeat(" ", width-n)
+}
+
+// stripANSI removes ANSI escape sequences from s.
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// displayWidth returns the number of terminal cells s occupies, ignoring
+// ANSI escape sequences and counting wide runes such as emoji as two cells.
+func displayWidth(s string) int {
+	return runewidth.StringWidth(stripANSI(s))
 }
 
 // RenderGrowthTable renders a table of file growth information.
 func RenderGrowthTable(files []types.FileGrowth) string {
-	table := NewTable("FILE", "GROWTH", "GROWTH/SEC")
+	return RenderGrowthTableColored(files, false)
+}
+
+// RenderGrowthTableColored renders a table of file growth information in
+// the active sort order, coloring the rate cell by severity when useColors
+// is set.
+func RenderGrowthTableColored(files []types.FileGrowth, useColors bool) string {
+	out := growthTable(files, useColors)
+	if len(files) > 0 {
+		out += "\n" + severityLegend()
+	}
+	return out
+}
 
+// RenderGrowthBySeverity renders one growth table per severity tier, most
+// severe first, each headed by the tier name and file count. Empty tiers
+// are omitted.
+func RenderGrowthBySeverity(files []types.FileGrowth, useColors bool) string {
+	tiers := activeScheme.Tiers
+	buckets := make([][]types.FileGrowth, len(tiers))
 	for _, f := range files {
+		level := int(activeScheme.Level(f.GrowthRate))
+		buckets[level] = append(buckets[level], f)
+	}
+
+	var sections []string
+	for i := len(tiers) - 1; i >= 0; i-- {
+		if len(buckets[i]) == 0 {
+			continue
+		}
+		title := fmt.Sprintf("%s %s (%d)", tiers[i].Emoji, strings.ToUpper(tiers[i].Name), len(buckets[i]))
+		if useColors {
+			title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(tiers[i].Color)).Render(title)
+		}
+		sections = append(sections, title+"\n"+growthTable(buckets[i], useColors))
+	}
+
+	return strings.Join(sections, "\n\n")
+}
+
+// growthTable renders the growth table body shared by the growth renderers.
+func growthTable(files []types.FileGrowth, useColors bool) string {
+	files = sortedForDisplay(files)
+	table := NewTable("FILE", "GROWTH", "GROWTH/SEC")
+	header, labels := pathLabels(files)
+
+	top := len(activeScheme.Tiers) - 1
+
+	for i, f := range files {
 		emoji := GetSeverityEmoji(f.GrowthRate)
+		path := growthPath(f, labels[i], 40)
+		growth := util.FormatBytesWithSign(f.GrowthBytes)
+		rate := util.FormatRate(f.GrowthRate)
+		if useColors {
+			style := lipgloss.NewStyle().Bold(true).Foreground(GetSeverityColor(f.GrowthRate))
+			rate = style.Render(rate)
+			// Make the most severe rows stand out as a whole
+			if top > 0 && int(activeScheme.Level(f.GrowthRate)) == top {
+				path = style.Render(path)
+				growth = style.Render(growth)
+			}
+		}
+		table.AddRow(path, growth, fmt.Sprintf("%s %s", emoji, rate))
+	}
+
+	return header + table.Render()
+}
+
+// severityLegend explains the active severity tiers, e.g.
+// "🟢 <1.0 MB/s  🟡 1.0 MB/s–10.0 MB/s  🔴 >=10.0 MB/s".
+func severityLegend() string {
+	tiers := activeScheme.Tiers
+	parts := make([]string, 0, len(tiers))
+	for i, t := range tiers {
+		var bounds string
+		switch {
+		case len(tiers) == 1:
+			bounds = "any rate"
+		case i == 0:
+			bounds = "<" + util.FormatRate(tiers[1].MinRate)
+		case i == len(tiers)-1:
+			bounds = ">=" + util.FormatRate(t.MinRate)
+		default:
+			bounds = util.FormatRate(t.MinRate) + "–" + util.FormatRate(tiers[i+1].MinRate)
+		}
+		parts = append(parts, t.Emoji+" "+bounds)
+	}
+	return strings.Join(parts, "  ") + "\n"
+}
+
+// RenderSizeTable renders a table of files by size.
+func RenderSizeTable(files []types.FileInfo) string {
+	table := NewTable("FILE", "SIZE", "MODIFIED")
+
+	for _, f := range files {
 		table.AddRow(
 			truncatePath(f.Path, 40),
+			util.FormatBytes(f.Size),
+			formatTime(f.ModTime),
+		)
+	}
+
+	return table.Render()
+}
+
+// RenderSizeTableUncompressed renders a table of files by size with an
+// extra column giving the uncompressed size of gzip and zstd files, read
+// from their headers without decompressing. Other files show "-".
+func RenderSizeTableUncompressed(files []types.FileInfo) string {
+	table := NewTable("FILE", "SIZE", "UNCOMPRESSED", "MODIFIED")
+
+	for _, f := range files {
+		uncompressed := "-"
+		if n, err := util.UncompressedSize(f.Path); err == nil {
+			uncompressed = util.FormatBytes(n)
+		}
+		table.AddRow(
+			truncatePath(f.Path, 40),
+			util.FormatBytes(f.Size),
+			uncompressed,
+			formatTime(f.ModTime),
+		)
+	}
+
+	return table.Render()
+}
+
+// RenderInsecureFiles renders a security section listing files with risky
+// permissions. It returns an empty string when there are none.
+func RenderInsecureFiles(files []types.FileInfo) string {
+	if len(files) == 0 {
+		return ""
+	}
+
+	table := NewTable("FILE", "MODE", "ISSUE")
+	for _, f := range files {
+		table.AddRow(
+			truncatePath(f.Path, 40),
+			fmt.Sprintf("%04o", f.Permission),
+			strings.Join(permissionIssues(f), ", "),
+		)
+	}
+
+	return WarningStyle.Render("⚠ Security findings") + "\n" + table.Render()
+}
+
+// permissionIssues describes what is risky about a file's permissions.
+func permissionIssues(f types.FileInfo) []string {
+	var issues []string
+	if f.Permission&0002 != 0 {
+		issues = append(issues, "world-writable")
+	}
+	if f.Permission&04000 != 0 {
+		issues = append(issues, "setuid")
+	}
+	if f.Permission&02000 != 0 {
+		issues = append(issues, "setgid")
+	}
+	if len(issues) == 0 && f.Permission&0004 != 0 {
+		issues = append(issues, "world-readable sensitive log")
+	}
+	return issues
+}
+
+// RenderGrowthPatternTable renders file growth with each file's growth pattern,
+// in the active sort order.
+func RenderGrowthPatternTable(files []types.FileGrowth, patterns map[string]types.GrowthPattern) string {
+	files = sortedForDisplay(files)
+	table := NewTable("FILE", "GROWTH", "GROWTH/SEC", "PATTERN")
+	header, labels := pathLabels(files)
+
+	for i, f := range files {
+		pattern := "-"
+		if p, ok := patterns[f.Path]; ok {
+			pattern = p.String()
+		}
+		table.AddRow(
+			growthPath(f, labels[i], 40),
 			util.FormatBytesWithSign(f.GrowthBytes),
-			fmt.Sprintf("%s %s", emoji, util.FormatRate(f.GrowthRate)),
+			fmt.Sprintf("%s %s", GetSeverityEmoji(f.GrowthRate), util.FormatRate(f.GrowthRate)),
+			pattern,
+		)
+	}
+
+	return header + table.Render()
+}
+
+// RenderLiveRate renders a single status line for a watched file.
+func RenderLiveRate(g types.FileGrowth) string {
+	return fmt.Sprintf("%s %-12s %-10s size %-10s %s",
+		GetSeverityEmoji(g.GrowthRate),
+		util.FormatRate(g.GrowthRate),
+		util.FormatBytesWithSign(g.GrowthBytes),
+		util.FormatBytes(g.FinalSize),
+		g.Path+fsMarker(g),
+	)
+}
+
+// RenderTopWriters renders processes ranked by write rate.
+func RenderTopWriters(procs []types.ProcessInfo) string {
+	table := NewTable("PID", "NAME", "WRITE/SEC", "COMMAND")
+
+	for _, p := range procs {
+		cmd := p.Cmdline
+		if cmd == "" {
+			cmd = p.Name
+		}
+		table.AddRow(
+			fmt.Sprintf("%d", p.PID),
+			truncate(p.Name, 20),
+			fmt.Sprintf("%s %s", GetSeverityEmoji(p.WriteRate), util.FormatRate(p.WriteRate)),
+			truncate(cmd, 50),
 		)
 	}
 
 	return table.Render()
 }
 
-// RenderProcessInfo renders process information in a box.
-func RenderProcessInfo(info types.ProcessInfo) string {
+// RenderGrowthDiff renders a side-by-side comparison of a problem host and
+// a healthy peer. Paths growing on only one side are marked and, with
+// useColors, highlighted.
+func RenderGrowthDiff(diffs []types.GrowthDiff, problemHost, healthyHost string, useColors bool) string {
+	table := NewTable("FILE", strings.ToUpper(problemHost), strings.ToUpper(healthyHost), "")
+
+	for _, d := range diffs {
+		path := truncatePath(d.Path, 40)
+		note := ""
+		switch {
+		case d.OnlyProblem():
+			note = "only on " + problemHost
+			if useColors {
+				path = ErrorStyle.Render(path)
+			}
+		case d.OnlyHealthy():
+			note = "only on " + healthyHost
+		}
+		table.AddRow(path, diffRate(d.ProblemRate), diffRate(d.HealthyRate), note)
+	}
+
+	return table.Render()
+}
+
+// diffRate formats one side of a growth diff.
+func diffRate(rate float64) string {
+	if rate <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%s %s", GetSeverityEmoji(rate), util.FormatRate(rate))
+}
+
+// Styles for the change kinds in a snapshot diff.
+var (
+	diffGrewStyle   = lipgloss.NewStyle().Foreground(ColorGreen)
+	diffShrankStyle = lipgloss.NewStyle().Foreground(ColorRed)
+	diffNewStyle    = lipgloss.NewStyle().Foreground(ColorCyan)
+	diffGoneStyle   = lipgloss.NewStyle().Foreground(ColorGray)
+)
+
+// RenderSnapshotDiff renders every file whose size changed between two
+// snapshots, not just those crossing the thresholds: grown (green +),
+// shrunk (red -), new (cyan) and removed (gray), sorted by path. It returns
+// "" when nothing changed.
+func RenderSnapshotDiff(snap1, snap2 *types.Snapshot) string {
+	paths := make([]string, 0, len(snap2.Files))
+	for path, info := range snap2.Files {
+		if !info.IsDir {
+			paths = append(paths, path)
+		}
+	}
+	for path, info := range snap1.Files {
+		if _, ok := snap2.Files[path]; !ok && !info.IsDir {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	table := NewTable("", "FILE", "BEFORE", "AFTER", "CHANGE")
+	for _, path := range paths {
+		before, existed := snap1.Files[path]
+		after, exists := snap2.Files[path]
+
+		var mark string
+		var style lipgloss.Style
+		switch {
+		case !existed:
+			mark, style = "+", diffNewStyle
+		case !exists:
+			mark, style = "-", diffGoneStyle
+		case after.Size > before.Size:
+			mark, style = "+", diffGrewStyle
+		case after.Size < before.Size:
+			mark, style = "-", diffShrankStyle
+		default:
+			continue
+		}
+
+		beforeSize, afterSize := "-", "-"
+		if existed {
+			beforeSize = util.FormatBytes(before.Size)
+		}
+		if exists {
+			afterSize = util.FormatBytes(after.Size)
+		}
+
+		table.AddRow(
+			style.Render(mark),
+			style.Render(truncatePath(path, 50)),
+			beforeSize,
+			afterSize,
+			style.Render(util.FormatBytesWithSign(after.Size-before.Size)),
+		)
+	}
+
+	if len(table.rows) == 0 {
+		return ""
+	}
+	return table.Render()
+}
+
+// RenderSessionReport renders a summary of a monitoring session.
+func RenderSessionReport(report types.SessionReport) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("│ PID:          %-40d │\n", info.PID))
-	sb.WriteString(fmt.Sprintf("│ Process:      %-40s │\n", info.Name))
-	sb.WriteString(fmt.Sprintf("│ Command:      %-40s │\n", truncate(info.Cmdline, 40)))
-	sb.WriteString(fmt.Sprintf("│ User:         %-40s │\n", info.User))
-	sb.WriteString(fmt.Sprintf("│ Started:      %-40s │\n", info.StartTime.Format("2006-01-02 15:04:05")))
-	sb.WriteString(fmt.Sprintf("│ CPU:          %-40.1f%% │\n", info.CPUPercent))
-	sb.WriteString(fmt.Sprintf("│ Memory:       %-40s │\n", fmt.Sprintf("%.1f MB", info.MemoryMB)))
+	sb.WriteString(fmt.Sprintf("Session %s – %s (%s), %d scans, %s total growth\n",
+		formatTime(report.Start),
+		formatTime(report.End),
+		report.End.Sub(report.Start).Round(time.Second),
+		report.Scans,
+		util.FormatBytesWithSign(report.TotalGrowth),
+	))
+
+	if len(report.Files) == 0 {
+		return sb.String()
+	}
+
+	table := NewTable("FILE", "FLAGGED", "PEAK/SEC", "GROWTH", "FIRST SEEN", "LAST SEEN")
+	for _, f := range report.Files {
+		table.AddRow(
+			truncatePath(f.Path, 40),
+			fmt.Sprintf("%d/%d", f.Occurrences, report.Scans),
+			fmt.Sprintf("%s %s", GetSeverityEmoji(f.PeakRate), util.FormatRate(f.PeakRate)),
+			util.FormatBytesWithSign(f.TotalGrowth),
+			formatTime(f.FirstSeen),
+			formatTime(f.LastSeen),
+		)
+	}
+	sb.WriteString(table.Render())
 
-	return BoxStyle.Render(sb.String())
+	return sb.String()
+}
+
+// maxProcessValueWidth bounds the value column of the process box; longer
+// values such as command lines are ellipsized.
+const maxProcessValueWidth = 60
+
+// RenderProcessInfo renders process information in a box. Values are
+// padded by display width and long ones are ellipsized to keep the box
+// intact.
+func RenderProcessInfo(info types.ProcessInfo) string {
+	rows := [][2]string{
+		{"PID:", fmt.Sprintf("%d", info.PID)},
+		{"Process:", info.Name},
+		{"Command:", info.Cmdline},
+		{"User:", info.User},
+		{"Started:", formatTime(info.StartTime)},
+		{"CPU:", fmt.Sprintf("%.1f%%", info.CPUPercent)},
+		{"Memory:", fmt.Sprintf("%.1f MB", info.MemoryMB)},
+	}
+	if info.WriteBytes > 0 {
+		rows = append(rows, [2]string{"Written:", fmt.Sprintf("%s (%s to disk)",
+			util.FormatBytes(info.WriteBytes), util.FormatBytes(info.DiskWriteBytes))})
+	}
+	if info.BlockIODelay > 0 {
+		rows = append(rows, [2]string{"I/O wait:", info.BlockIODelay.Round(time.Millisecond).String()})
+	}
+
+	labelWidth := 0
+	for _, r := range rows {
+		if w := displayWidth(r[0]); w > labelWidth {
+			labelWidth = w
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, r := range rows {
+		lines[i] = padRight(r[0], labelWidth) + "  " + truncate(r[1], maxProcessValueWidth)
+	}
+
+	return BoxStyle.Render(strings.Join(lines, "\n"))
+}
+
+// tmpfsMarker flags files whose growth consumes RAM rather than disk.
+const tmpfsMarker = " [tmpfs]"
+
+// fsMarker returns the indicator for the file's filesystem, if any.
+func fsMarker(f types.FileGrowth) string {
+	if f.FilesystemType == "tmpfs" {
+		return tmpfsMarker
+	}
+	return ""
+}
+
+// growthPath returns a file's display label with its filesystem marker,
+// truncated to maxLen. Directories get a trailing separator.
+func growthPath(f types.FileGrowth, label string, maxLen int) string {
+	marker := fsMarker(f)
+	if f.IsDir {
+		label += string(filepath.Separator)
+	}
+	return truncatePath(label, maxLen-len(marker)) + marker
 }
 
 func truncatePath(path string, maxLen int) string {
-	if len(path) <= maxLen {
+	if displayWidth(path) <= maxLen {
 		return path
 	}
-	return "..." + path[len(path)-maxLen+3:]
+	tail := path
+	for tail != "" && displayWidth(tail) > maxLen-3 {
+		_, size := utf8.DecodeRuneInString(tail)
+		tail = tail[size:]
+	}
+	return "..." + tail
 }
 
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if displayWidth(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return runewidth.Truncate(s, maxLen, "...")
 }
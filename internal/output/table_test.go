@@ -0,0 +1,73 @@
+package output
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// borderColumns returns the terminal columns holding border junctions in a
+// rendered table line.
+func borderColumns(line string) []int {
+	var cols []int
+	col := 0
+	for _, r := range stripANSI(line) {
+		if strings.ContainsRune("│┌┬┐├┼┤└┴┘", r) {
+			cols = append(cols, col)
+		}
+		col += runewidth.RuneWidth(r)
+	}
+	return cols
+}
+
+func TestTableBordersAlign(t *testing.T) {
+	tests := []struct {
+		name string
+		rows [][]string
+	}{
+		{"ascii", [][]string{{"/var/log/syslog", "1.0 MB"}, {"a", "b"}}},
+		{"emoji", [][]string{{"🔴 /var/log/app.log", "12.0 MB/s"}, {"🟢 ok", "1 B/s"}}},
+		{"wide", [][]string{{"/var/log/日本語.log", "3.0 MB"}, {"ログ", "—"}}},
+		{"ansi", [][]string{{"\x1b[1;31mred\x1b[0m", "\x1b[38;2;255;0;0m12.0 MB/s\x1b[0m"}, {"plain", "x"}}},
+		{"mixed", [][]string{{"\x1b[31m🔴 日本\x1b[0m", "é"}, {"", ""}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := NewTable("Path", "Rate")
+			for _, row := range tt.rows {
+				table.AddRow(row...)
+			}
+
+			lines := strings.Split(table.Render(), "\n")
+			want := borderColumns(lines[0])
+			for i, line := range lines {
+				if got := borderColumns(line); !reflect.DeepEqual(got, want) {
+					t.Errorf("line %d %q: borders at %v, want %v", i, line, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abc", 3},
+		{"🔴", 2},
+		{"日本", 4},
+		{"é", 1},
+		{"\x1b[1;31mred\x1b[0m", 3},
+		{"\x1b[31m🔴 x\x1b[0m", 4},
+	}
+
+	for _, tt := range tests {
+		if got := displayWidth(tt.in); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
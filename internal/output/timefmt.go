@@ -0,0 +1,57 @@
+package output
+
+import "time"
+
+// Timestamp layouts for SetTimeFormat.
+const (
+	TimeDefault = "2006-01-02 15:04:05"
+	TimeRFC3339 = time.RFC3339
+)
+
+// timeLayout and timeUTC control how renderers print timestamps.
+var (
+	timeLayout = TimeDefault
+	timeUTC    = false
+)
+
+// ParseTimeFormat maps a preset name ("default" or "rfc3339") to its
+// layout. Any other value is taken as a Go time layout.
+func ParseTimeFormat(s string) string {
+	switch s {
+	case "", "default":
+		return TimeDefault
+	case "rfc3339":
+		return TimeRFC3339
+	default:
+		return s
+	}
+}
+
+// SetTimeFormat sets the layout used for timestamps and whether they are
+// converted to UTC first. An empty layout selects TimeDefault. JSON output
+// always uses RFC 3339 but honours the UTC setting.
+func SetTimeFormat(layout string, utc bool) {
+	if layout == "" {
+		layout = TimeDefault
+	}
+	timeLayout = layout
+	timeUTC = utc
+}
+
+// ActiveTimeFormat returns the current timestamp layout and UTC setting.
+func ActiveTimeFormat() (layout string, utc bool) {
+	return timeLayout, timeUTC
+}
+
+// formatTime formats t with the active layout.
+func formatTime(t time.Time) string {
+	return zone(t).Format(timeLayout)
+}
+
+// zone converts t to UTC when the UTC setting is on.
+func zone(t time.Time) time.Time {
+	if timeUTC {
+		return t.UTC()
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+// Package procstat parses /proc/[pid]/stat.
+package procstat
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// minFields is the number of fields after comm up to and including
+// starttime, present in every kernel's /proc/[pid]/stat.
+const minFields = 20
+
+// Error reports a /proc/[pid]/stat file that couldn't be parsed.
+type Error struct {
+	PID    int32
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("malformed /proc/%d/stat: %s", e.PID, e.Reason)
+}
+
+// Stat is a parsed /proc/[pid]/stat line.
+type Stat struct {
+	PID   int32
+	Comm  string
+	State string
+	PPID  int32
+
+	// fields holds the fields after comm; fields[0] is field 3, state.
+	fields []string
+}
+
+// Read reads and parses /proc/[pid]/stat.
+func Read(pid int32) (*Stat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, pid)
+}
+
+// Parse parses the contents of /proc/[pid]/stat. comm may contain spaces,
+// parentheses and even newlines, so it is delimited by the first "(" and
+// the last ")". The fields up to starttime are validated rather than
+// trusted, so a truncated or garbled file yields an *Error instead of
+// plausible but wrong values.
+func Parse(data []byte, pid int32) (*Stat, error) {
+	content := string(data)
+	if !strings.HasSuffix(content, "\n") {
+		return nil, &Error{PID: pid, Reason: "truncated"}
+	}
+
+	open := strings.Index(content, " (")
+	closeParen := strings.LastIndex(content, ")")
+	if open == -1 || closeParen < open {
+		return nil, &Error{PID: pid, Reason: "comm not found"}
+	}
+
+	if got, err := strconv.ParseInt(content[:open], 10, 32); err != nil || int32(got) != pid {
+		return nil, &Error{PID: pid, Reason: fmt.Sprintf("pid field %q", content[:open])}
+	}
+
+	// Fields after comm: state ppid pgrp session ...
+	fields := strings.Fields(content[closeParen+1:])
+	if len(fields) < minFields {
+		return nil, &Error{PID: pid, Reason: fmt.Sprintf("%d fields after comm, want at least %d", len(fields), minFields)}
+	}
+	if len(fields[0]) != 1 {
+		return nil, &Error{PID: pid, Reason: fmt.Sprintf("state field %q", fields[0])}
+	}
+	for _, f := range fields[1:minFields] {
+		if _, err := strconv.ParseInt(f, 10, 64); err != nil {
+			return nil, &Error{PID: pid, Reason: fmt.Sprintf("non-numeric field %q", f)}
+		}
+	}
+
+	ppid, err := strconv.ParseInt(fields[1], 10, 32)
+	if err != nil || ppid < 0 {
+		return nil, &Error{PID: pid, Reason: fmt.Sprintf("ppid field %q", fields[1])}
+	}
+
+	return &Stat{
+		PID:    pid,
+		Comm:   content[open+2 : closeParen],
+		State:  fields[0],
+		PPID:   int32(ppid),
+		fields: fields,
+	}, nil
+}
+
+// Field returns numeric field n, counting from 1 as proc(5) does. Fields
+// added by newer kernels may be missing.
+func (s *Stat) Field(n int) (int64, error) {
+	i := n - 3
+	if i < 1 || i >= len(s.fields) {
+		return 0, &Error{PID: s.PID, Reason: fmt.Sprintf("no field %d", n)}
+	}
+	v, err := strconv.ParseInt(s.fields[i], 10, 64)
+	if err != nil {
+		return 0, &Error{PID: s.PID, Reason: fmt.Sprintf("non-numeric field %d %q", n, s.fields[i])}
+	}
+	return v, nil
+}
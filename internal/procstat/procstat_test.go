@@ -0,0 +1,82 @@
+package procstat
+
+import (
+	"errors"
+	"testing"
+)
+
+// statTail is the part of a /proc/[pid]/stat line after "ppid", up to and
+// including starttime and a few later fields.
+const statTail = " 4242 4242 0 -1 4194560 1200 0 0 0 12 3 0 0 20 0 1 0 98765 1000000 300 18446744073709551615\n"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    int32
+		wantErr bool
+	}{
+		{"simple", "4242 (nginx) S 1" + statTail, 1, false},
+		{"space in comm", "4242 (my worker) S 17" + statTail, 17, false},
+		{"paren and space in comm", "4242 (a) S 99 (b) R 17" + statTail, 17, false},
+		{"only parens in comm", "4242 ()) R 5" + statTail, 5, false},
+		{"empty comm", "4242 () S 5" + statTail, 5, false},
+		{"newline in comm", "4242 (evil\n) S 17" + statTail, 17, false},
+		{"zero ppid", "4242 (init) S 0" + statTail, 0, false},
+		{"truncated", "4242 (nginx) S 1 4242", 0, true},
+		{"no comm", "4242 nginx S 1" + statTail, 0, true},
+		{"wrong pid", "4243 (nginx) S 1" + statTail, 0, true},
+		{"too few fields", "4242 (nginx) S 1 4242\n", 0, true},
+		{"bad state", "4242 (nginx) SS 1" + statTail, 0, true},
+		{"non-numeric ppid", "4242 (nginx) S x" + statTail, 0, true},
+		{"negative ppid", "4242 (nginx) S -1" + statTail, 0, true},
+		{"comm hides fields", "4242 (a) S 1" + statTail[:len(statTail)-1] + ")\n", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stat, err := Parse([]byte(tt.data), 4242)
+			if tt.wantErr {
+				var statErr *Error
+				if !errors.As(err, &statErr) {
+					t.Fatalf("Parse = %+v, %v; want an *Error", stat, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if stat.PPID != tt.want {
+				t.Errorf("PPID = %d, want %d", stat.PPID, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatField(t *testing.T) {
+	stat, err := Parse([]byte("4242 (my (worker)) S 1"+statTail), 4242)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Comm != "my (worker)" || stat.State != "S" {
+		t.Errorf("comm %q state %q, want \"my (worker)\" S", stat.Comm, stat.State)
+	}
+
+	tests := []struct {
+		field   int
+		want    int64
+		wantErr bool
+	}{
+		{4, 1, false},      // ppid
+		{22, 98765, false}, // starttime
+		{24, 300, false},   // rss
+		{3, 0, true},       // state isn't numeric
+		{42, 0, true},      // not reported by this kernel
+	}
+	for _, tt := range tests {
+		got, err := stat.Field(tt.field)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Field(%d) = %d, %v; want %d, error %v", tt.field, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
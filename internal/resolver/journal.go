@@ -0,0 +1,76 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JournaldUnit is the unit that owns every binary journal file.
+const JournaldUnit = "systemd-journald.service"
+
+// journalDirs are where journald keeps persistent and volatile journals.
+var journalDirs = []string{"/var/log/journal", "/run/log/journal"}
+
+// IsJournalFile reports whether path is a journald binary log. Journald
+// writes through mmap and holds its files open only intermittently, so these
+// files are attributed by location rather than by open file descriptors.
+func IsJournalFile(path string) bool {
+	path = filepath.Clean(path)
+	name := filepath.Base(path)
+	if !strings.HasSuffix(name, ".journal") && !strings.HasSuffix(name, ".journal~") {
+		return false
+	}
+	for _, dir := range journalDirs {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// diskUsageRe matches the size in journalctl --disk-usage output, e.g.
+// "Archived and active journals take up 1.2G in the file system."
+var diskUsageRe = regexp.MustCompile(`take up ([0-9.]+)([BKMGTPE]?)`)
+
+// JournalDiskUsage returns the bytes used by all journal files as reported
+// by journalctl --disk-usage.
+func JournalDiskUsage(ctx context.Context) (int64, error) {
+	path, err := exec.LookPath("journalctl")
+	if err != nil {
+		return 0, err
+	}
+
+	output, err := exec.CommandContext(ctx, path, "--disk-usage").Output()
+	if err != nil {
+		return 0, err
+	}
+
+	return parseDiskUsage(string(output))
+}
+
+// parseDiskUsage extracts the size from journalctl --disk-usage output.
+// journalctl formats sizes with binary multiples.
+func parseDiskUsage(output string) (int64, error) {
+	m := diskUsageRe.FindStringSubmatch(output)
+	if m == nil {
+		return 0, fmt.Errorf("unrecognized journalctl output: %q", strings.TrimSpace(output))
+	}
+
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	mult := float64(1)
+	if m[2] != "" && m[2] != "B" {
+		shift := strings.Index("KMGTPE", m[2]) + 1
+		mult = float64(int64(1) << (10 * shift))
+	}
+
+	return int64(value * mult), nil
+}
@@ -0,0 +1,28 @@
+package resolver
+
+import "testing"
+
+func TestIsJournalFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/var/log/journal/0123abcd/system.journal", true},
+		{"/var/log/journal/0123abcd/user-1000@0005f1-0a.journal~", true},
+		{"/run/log/journal/0123abcd/system.journal", true},
+		{"/var/log//journal/0123abcd/system.journal", true},
+		{"/var/log/./journal/0123abcd/system.journal", true},
+		{"/var/log/app/../journal/0123abcd/system.journal", true},
+		{"/var/log/journal/../app/fake.journal", false},
+		{"/var/log/journal/0123abcd/system.journal.gz", false},
+		{"/var/log/journalx/system.journal", false},
+		{"/srv/data/system.journal", false},
+		{"var/log/journal/0123abcd/system.journal", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsJournalFile(tt.path); got != tt.want {
+			t.Errorf("IsJournalFile(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
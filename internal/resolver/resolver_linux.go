@@ -0,0 +1,166 @@
+//go:build linux
+
+package resolver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Resolver resolves PIDs to systemd services.
+type Resolver struct {
+	conn *dbus.Conn
+}
+
+// New creates a new Resolver.
+func New() (*Resolver, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		// D-Bus not available, will use fallback
+		return &Resolver{conn: nil}, nil
+	}
+	return &Resolver{conn: conn}, nil
+}
+
+// Close closes the D-Bus connection.
+func (r *Resolver) Close() {
+	if r.conn != nil {
+		r.conn.Close()
+	}
+}
+
+// ResolveService resolves a PID to its systemd service.
+func (r *Resolver) ResolveService(pid int32) (*types.ServiceInfo, error) {
+	if r.conn != nil {
+		// Try systemd first
+		info, err := r.resolveWithSystemd(pid)
+		if err == nil && info != nil {
+			return info, nil
+		}
+	}
+
+	// Fallback to process tree analysis
+	return r.resolveFromProcessTree(pid)
+}
+
+// resolveWithSystemd uses D-Bus to query systemd.
+func (r *Resolver) resolveWithSystemd(pid int32) (*types.ServiceInfo, error) {
+	obj := r.conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
+
+	var unitPath dbus.ObjectPath
+	err := obj.Call("org.freedesktop.systemd1.Manager.GetUnitByPID", 0, uint32(pid)).Store(&unitPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.unitInfo(unitPath), nil
+}
+
+// unitInfo reads the properties of a loaded unit.
+func (r *Resolver) unitInfo(unitPath dbus.ObjectPath) *types.ServiceInfo {
+	unitObj := r.conn.Object("org.freedesktop.systemd1", unitPath)
+
+	var unitName string
+	err := unitObj.Call("org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Unit", "Id").Store(&unitName)
+	if err != nil {
+		unitName = string(unitPath)
+	}
+
+	var activeState string
+	_ = unitObj.Call("org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Unit", "ActiveState").Store(&activeState)
+
+	var mainPID uint32
+	_ = unitObj.Call("org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Service", "MainPID").Store(&mainPID)
+
+	var description string
+	_ = unitObj.Call("org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Unit", "Description").Store(&description)
+
+	return &types.ServiceInfo{
+		Unit:        unitName,
+		Status:      activeState,
+		MainPID:     int32(mainPID),
+		Description: description,
+	}
+}
+
+// GetServiceStatus returns the status of a systemd service.
+func (r *Resolver) GetServiceStatus(unitName string) (string, error) {
+	if r.conn == nil {
+		return "unknown", fmt.Errorf("D-Bus not available")
+	}
+
+	obj := r.conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
+
+	var unitPath dbus.ObjectPath
+	err := obj.Call("org.freedesktop.systemd1.Manager.GetUnit", 0, unitName).Store(&unitPath)
+	if err != nil {
+		return "unknown", err
+	}
+
+	unitObj := r.conn.Object("org.freedesktop.systemd1", unitPath)
+
+	var activeState string
+	err = unitObj.Call("org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Unit", "ActiveState").Store(&activeState)
+	if err != nil {
+		return "unknown", err
+	}
+
+	return activeState, nil
+}
+
+// GetServiceStartTime returns when a service was started.
+func (r *Resolver) GetServiceStartTime(unitName string) (time.Time, error) {
+	if r.conn == nil {
+		return time.Time{}, fmt.Errorf("D-Bus not available")
+	}
+
+	obj := r.conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
+
+	var unitPath dbus.ObjectPath
+	err := obj.Call("org.freedesktop.systemd1.Manager.GetUnit", 0, unitName).Store(&unitPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	unitObj := r.conn.Object("org.freedesktop.systemd1", unitPath)
+
+	var timestamp uint64
+	err = unitObj.Call("org.freedesktop.DBus.Properties.Get", 0,
+		"org.freedesktop.systemd1.Unit", "ActiveEnterTimestamp").Store(&timestamp)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Timestamp is in microseconds since epoch
+	return time.Unix(int64(timestamp/1000000), int64(timestamp%1000000)*1000), nil
+}
+
+// ResolveUnit returns information about a named unit. Without D-Bus only
+// the unit name is known and Status is FallbackStatus.
+func (r *Resolver) ResolveUnit(unitName string) (*types.ServiceInfo, error) {
+	if r.conn == nil {
+		return &types.ServiceInfo{Unit: unitName, Status: FallbackStatus}, nil
+	}
+
+	obj := r.conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1")
+
+	var unitPath dbus.ObjectPath
+	err := obj.Call("org.freedesktop.systemd1.Manager.GetUnit", 0, unitName).Store(&unitPath)
+	if err != nil {
+		return nil, err
+	}
+
+	info := r.unitInfo(unitPath)
+	if start, err := r.GetServiceStartTime(unitName); err == nil {
+		info.StartTime = start
+	}
+	return info, nil
+}
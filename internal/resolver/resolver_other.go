@@ -0,0 +1,43 @@
+//go:build !linux
+
+package resolver
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Resolver resolves PIDs to services. There is no systemd off Linux, so
+// services are only guessed from the process tree.
+type Resolver struct{}
+
+// New creates a new Resolver.
+func New() (*Resolver, error) {
+	return &Resolver{}, nil
+}
+
+// Close releases the Resolver's resources.
+func (r *Resolver) Close() {}
+
+// ResolveService resolves a PID to the service it belongs to.
+func (r *Resolver) ResolveService(pid int32) (*types.ServiceInfo, error) {
+	return r.resolveFromProcessTree(pid)
+}
+
+// GetServiceStatus requires systemd and is only supported on Linux.
+func (r *Resolver) GetServiceStatus(unitName string) (string, error) {
+	return "unknown", fmt.Errorf("service status is only supported on Linux")
+}
+
+// GetServiceStartTime requires systemd and is only supported on Linux.
+func (r *Resolver) GetServiceStartTime(unitName string) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("service start time is only supported on Linux")
+}
+
+// ResolveUnit returns the unit name with Status FallbackStatus, since
+// there is no systemd to ask.
+func (r *Resolver) ResolveUnit(unitName string) (*types.ServiceInfo, error) {
+	return &types.ServiceInfo{Unit: unitName, Status: FallbackStatus}, nil
+}
@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// DefaultAllowlistScans is how many consecutive unflagged comparisons make
+// a file stable.
+const DefaultAllowlistScans = 10
+
+// Allowlist learns which files never grow enough to be flagged. Once a
+// file has stayed below the thresholds across a number of consecutive
+// comparisons it is allowlisted with the largest growth it showed while
+// learning, and CalculateGrowth skips it as long as its growth stays
+// within that. Growing faster evicts it and learning starts over. Files
+// that disappear are forgotten.
+type Allowlist struct {
+	mu      sync.Mutex
+	scans   int
+	streaks map[string]streak
+	stable  map[string]int64 // allowlisted files and their largest learned growth
+}
+
+// streak is a file's run of unflagged comparisons so far.
+type streak struct {
+	count     int
+	maxGrowth int64
+}
+
+// NewAllowlist creates an allowlist that admits a file after scans
+// consecutive unflagged comparisons.
+func NewAllowlist(scans int) *Allowlist {
+	if scans <= 0 {
+		scans = DefaultAllowlistScans
+	}
+	return &Allowlist{
+		scans:   scans,
+		streaks: make(map[string]streak),
+		stable:  make(map[string]int64),
+	}
+}
+
+// skip reports whether an allowlisted file's growth is within what it
+// showed while learning, so it needn't be evaluated. A file that grew more
+// is evicted. The caller must hold a.mu.
+func (a *Allowlist) skip(path string, growth int64) bool {
+	limit, ok := a.stable[path]
+	if !ok {
+		return false
+	}
+	if growth <= limit {
+		return true
+	}
+	delete(a.stable, path)
+	return false
+}
+
+// learn records one evaluated comparison of a file present in both
+// snapshots. A flagged file starts over; one that stays unflagged for
+// a.scans comparisons in a row is allowlisted. The caller must hold a.mu.
+func (a *Allowlist) learn(path string, growth int64, flagged bool) {
+	if flagged {
+		delete(a.streaks, path)
+		return
+	}
+
+	st := a.streaks[path]
+	st.count++
+	st.maxGrowth = max(st.maxGrowth, growth)
+	if st.count >= a.scans {
+		delete(a.streaks, path)
+		a.stable[path] = st.maxGrowth
+		return
+	}
+	a.streaks[path] = st
+}
+
+// forget drops every file missing from snapshot. The caller must hold a.mu
+// and should skip this for a Truncated snapshot, which may just have
+// dropped them.
+func (a *Allowlist) forget(snapshot *types.Snapshot) {
+	for path := range a.streaks {
+		if _, ok := snapshot.Files[path]; !ok {
+			delete(a.streaks, path)
+		}
+	}
+	for path := range a.stable {
+		if _, ok := snapshot.Files[path]; !ok {
+			delete(a.stable, path)
+		}
+	}
+}
+
+// Paths returns the allowlisted files, sorted.
+func (a *Allowlist) Paths() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	paths := make([]string, 0, len(a.stable))
+	for path := range a.stable {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Reset forgets everything learned.
+func (a *Allowlist) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.streaks = make(map[string]streak)
+	a.stable = make(map[string]int64)
+}
+
+// SaveAllowlist stores the allowlisted files and their learned growth.
+// Streaks of files not yet allowlisted are not saved.
+func (s *SnapshotStore) SaveAllowlist(a *Allowlist, filename string) error {
+	a.mu.Lock()
+	data, err := json.MarshalIndent(a.stable, "", "  ")
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.backend.Put(filename, bytes.NewReader(data))
+}
+
+// LoadAllowlist loads an allowlist saved with SaveAllowlist. scans applies
+// to files learned from now on, as for NewAllowlist.
+func (s *SnapshotStore) LoadAllowlist(filename string, scans int) (*Allowlist, error) {
+	r, err := s.backend.Get(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	a := NewAllowlist(scans)
+	if err := json.NewDecoder(r).Decode(&a.stable); err != nil {
+		return nil, err
+	}
+	if a.stable == nil {
+		a.stable = make(map[string]int64)
+	}
+
+	return a, nil
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestAllowlistLearning(t *testing.T) {
+	config := DefaultConfig()
+	config.ThresholdBytes = 1000
+	s := New(config)
+	a := NewAllowlist(3)
+	s.SetAllowlist(a)
+
+	sizes := map[string]int64{"/log/quiet": 0, "/log/static": 500, "/log/busy": 0}
+	snap := snapshotOf(0, sizes)
+	step := func(quiet, busy int64) []string {
+		t.Helper()
+		sizes["/log/quiet"] += quiet
+		sizes["/log/busy"] += busy
+		next := snapshotOf(int(snap.Timestamp.Unix())+10, sizes)
+		var flagged []string
+		for _, g := range s.CalculateGrowth(snap, next, false) {
+			flagged = append(flagged, g.Path)
+		}
+		snap = next
+		sort.Strings(flagged)
+		return flagged
+	}
+
+	// Sub-threshold growth counts towards learning, not just no growth
+	step(100, 5000)
+	step(300, 5000)
+	step(200, 5000)
+	if got, want := a.Paths(), []string{"/log/quiet", "/log/static"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("allowlisted %v, want %v", got, want)
+	}
+	if limit := a.stable["/log/quiet"]; limit != 300 {
+		t.Errorf("learned growth for /log/quiet = %d, want 300", limit)
+	}
+
+	// Growth beyond what was learned evicts, and a flagged file is reported
+	if got, want := step(2000, 5000), []string{"/log/busy", "/log/quiet"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("flagged %v, want %v", got, want)
+	}
+	if got, want := a.Paths(), []string{"/log/static"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allowlisted %v after eviction, want %v", got, want)
+	}
+
+	// Files missing from the new snapshot are forgotten
+	delete(sizes, "/log/static")
+	step(0, 0)
+	if got := a.Paths(); len(got) != 0 {
+		t.Errorf("allowlisted %v after /log/static vanished, want none", got)
+	}
+	if _, ok := a.streaks["/log/static"]; ok {
+		t.Error("streak kept for vanished /log/static")
+	}
+}
+
+func TestAllowlistKeepsPathsMissingFromTruncatedSnapshot(t *testing.T) {
+	s := New(DefaultConfig())
+	a := NewAllowlist(1)
+	s.SetAllowlist(a)
+
+	snap1 := snapshotOf(0, map[string]int64{"/log/a": 10, "/log/b": 10})
+	snap2 := snapshotOf(10, map[string]int64{"/log/a": 10, "/log/b": 10})
+	s.CalculateGrowth(snap1, snap2, false)
+
+	snap3 := snapshotOf(20, map[string]int64{"/log/a": 10})
+	snap3.Truncated = true
+	s.CalculateGrowth(snap2, snap3, false)
+
+	if got, want := a.Paths(), []string{"/log/a", "/log/b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("allowlisted %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// SeveritySnapshotter saves the latest snapshot of a scan when any growing
+// file reaches a severity level, at most once per cooldown.
+type SeveritySnapshotter struct {
+	store    *SnapshotStore
+	level    types.SeverityLevel
+	cooldown time.Duration
+	scheme   types.SeverityScheme
+
+	mu       sync.Mutex
+	lastSave time.Time
+}
+
+// NewSeveritySnapshotter creates a snapshotter that saves to store when a
+// scan reaches level, using the default severity scheme.
+func NewSeveritySnapshotter(store *SnapshotStore, level types.SeverityLevel, cooldown time.Duration) *SeveritySnapshotter {
+	return &SeveritySnapshotter{
+		store:    store,
+		level:    level,
+		cooldown: cooldown,
+		scheme:   types.DefaultSeverityScheme(),
+	}
+}
+
+// SetScheme sets the severity scheme used to classify growth rates.
+func (a *SeveritySnapshotter) SetScheme(scheme types.SeverityScheme) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scheme = scheme
+}
+
+// Observe saves result.Snapshot2 if any growing file reaches the configured
+// severity and the cooldown has elapsed. It returns the saved filename, or
+// "" when nothing was saved.
+func (a *SeveritySnapshotter) Observe(result *types.ScanResult) (string, error) {
+	if result == nil || result.Snapshot2 == nil {
+		return "", nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	worst := types.SeverityLevel(-1)
+	for _, g := range result.GrowingFiles {
+		if level := a.scheme.Level(g.GrowthRate); level > worst {
+			worst = level
+		}
+	}
+	if worst < a.level {
+		return "", nil
+	}
+
+	now := time.Now()
+	if !a.lastSave.IsZero() && now.Sub(a.lastSave) < a.cooldown {
+		return "", nil
+	}
+
+	tier := "level" + fmt.Sprint(int(worst))
+	if int(worst) < len(a.scheme.Tiers) && a.scheme.Tiers[worst].Name != "" {
+		tier = a.scheme.Tiers[worst].Name
+	}
+	filename := fmt.Sprintf("snapshot-%s-%s.json",
+		result.Snapshot2.Timestamp.UTC().Format("20060102T150405Z"), tier)
+
+	if err := a.store.Save(result.Snapshot2, filename); err != nil {
+		return "", err
+	}
+	a.lastSave = now
+
+	return filename, nil
+}
@@ -0,0 +1,193 @@
+package scanner
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Defaults for BaselineTracker.
+const (
+	DefaultBaselineWindow = 30
+	DefaultBaselineSigma  = 3.0
+
+	// minBaselineSamples is the history needed before a file can be flagged.
+	minBaselineSamples = 5
+
+	// minStdDev keeps a perfectly steady file from producing infinite z-scores.
+	minStdDev = 1.0 // bytes per second
+)
+
+// BaselineTracker flags files whose growth rate is unusual for that file,
+// using a rolling mean and standard deviation of its recent growth. It is
+// safe for concurrent use.
+type BaselineTracker struct {
+	window int
+	sigma  float64
+
+	mu      sync.Mutex
+	samples map[string]*rateWindow
+}
+
+// rateWindow is a fixed-size ring of growth rates.
+type rateWindow struct {
+	values []float64
+	next   int
+	count  int
+}
+
+// NewBaselineTracker creates a tracker keeping window samples per file and
+// flagging growth above mean + sigma standard deviations.
+func NewBaselineTracker(window int, sigma float64) *BaselineTracker {
+	if window <= 0 {
+		window = DefaultBaselineWindow
+	}
+	if sigma <= 0 {
+		sigma = DefaultBaselineSigma
+	}
+	return &BaselineTracker{
+		window:  window,
+		sigma:   sigma,
+		samples: make(map[string]*rateWindow),
+	}
+}
+
+// Observe records each file's growth between two snapshots and returns the
+// files whose growth deviates from their baseline, sorted by z-score.
+func (b *BaselineTracker) Observe(snap1, snap2 *types.Snapshot) []types.FileGrowth {
+	interval := snap2.Timestamp.Sub(snap1.Timestamp)
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	var flagged []types.FileGrowth
+
+	for path, info2 := range snap2.Files {
+		if info2.IsDir {
+			continue
+		}
+		info1, exists := snap1.Files[path]
+		if !exists {
+			continue // No growth sample without a previous size
+		}
+
+		growth := info2.Size - info1.Size
+		g := types.FileGrowth{
+			Path:        path,
+			InitialSize: info1.Size,
+			FinalSize:   info2.Size,
+			GrowthBytes: growth,
+			GrowthRate:  float64(growth) / interval.Seconds(),
+			Interval:    interval,
+		}
+		if b.FlagGrowth(&g, info1, info2) {
+			flagged = append(flagged, g)
+		}
+	}
+
+	b.forget(snap2)
+
+	sort.Slice(flagged, func(i, j int) bool {
+		return flagged[i].ZScore > flagged[j].ZScore
+	})
+
+	return flagged
+}
+
+// ShouldFlag implements ThresholdStrategy, so a GrowthCalculator or Scanner
+// can flag by z-score. Like Observe it records a sample for the file, keyed
+// by info2.Path, on every call; new files are never flagged. A Scanner
+// drops the history of files missing from each complete snapshot it
+// compares, as Observe does.
+func (b *BaselineTracker) ShouldFlag(info1, info2 types.FileInfo, interval time.Duration) bool {
+	g := types.FileGrowth{
+		Path:       info2.Path,
+		GrowthRate: float64(info2.Size-info1.Size) / interval.Seconds(),
+	}
+	return b.FlagGrowth(&g, info1, info2)
+}
+
+// FlagGrowth implements Reasoner like ShouldFlag, keyed by g.Path, and sets
+// the z-score of a flagged file.
+func (b *BaselineTracker) FlagGrowth(g *types.FileGrowth, info1, _ types.FileInfo) bool {
+	if info1 == (types.FileInfo{}) {
+		return false // No growth sample without a previous size
+	}
+	z, ok := b.score(g.Path, g.GrowthRate)
+	if !ok || z <= b.sigma {
+		return false
+	}
+	g.ZScore = z
+	g.Reason = fmt.Sprintf("z-score %.1f", z)
+	return true
+}
+
+// forget drops the history of files missing from snapshot, so renamed and
+// rotated-away files don't accumulate.
+func (b *BaselineTracker) forget(snapshot *types.Snapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for path := range b.samples {
+		if _, ok := snapshot.Files[path]; !ok {
+			delete(b.samples, path)
+		}
+	}
+}
+
+// score returns a rate's z-score against the file's history, if it has
+// enough samples, and then adds the rate to the history.
+func (b *BaselineTracker) score(path string, rate float64) (z float64, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	w, exists := b.samples[path]
+	if !exists {
+		w = &rateWindow{values: make([]float64, b.window)}
+		b.samples[path] = w
+	}
+	defer w.add(rate)
+
+	if w.count < minBaselineSamples {
+		return 0, false
+	}
+	mean, stddev := w.stats()
+	if stddev < minStdDev {
+		stddev = minStdDev
+	}
+	return (rate - mean) / stddev, true
+}
+
+// add records a sample, overwriting the oldest when the window is full.
+func (w *rateWindow) add(v float64) {
+	w.values[w.next] = v
+	w.next = (w.next + 1) % len(w.values)
+	if w.count < len(w.values) {
+		w.count++
+	}
+}
+
+// stats returns the mean and population standard deviation of the window.
+func (w *rateWindow) stats() (mean, stddev float64) {
+	if w.count == 0 {
+		return 0, 0
+	}
+
+	var sum float64
+	for i := 0; i < w.count; i++ {
+		sum += w.values[i]
+	}
+	mean = sum / float64(w.count)
+
+	var sq float64
+	for i := 0; i < w.count; i++ {
+		d := w.values[i] - mean
+		sq += d * d
+	}
+	stddev = math.Sqrt(sq / float64(w.count))
+
+	return mean, stddev
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// BudgetTracker accumulates growth across a sliding time window and reports
+// when the cumulative growth of a file, or of all files together, exceeds
+// its budget.
+type BudgetTracker struct {
+	window      time.Duration
+	fileBudget  int64
+	totalBudget int64
+	intervals   []budgetInterval
+}
+
+// budgetInterval is the growth seen between one pair of snapshots.
+type budgetInterval struct {
+	end    time.Time
+	growth map[string]int64
+}
+
+// NewBudgetTracker creates a tracker allowing fileBudget bytes of growth per
+// file and totalBudget bytes overall within window. A budget of zero or less
+// disables that check.
+func NewBudgetTracker(window time.Duration, fileBudget, totalBudget int64) *BudgetTracker {
+	return &BudgetTracker{
+		window:      window,
+		fileBudget:  fileBudget,
+		totalBudget: totalBudget,
+	}
+}
+
+// Observe records the growth between two snapshots and returns every budget
+// currently exceeded, largest growth first. Shrinking files contribute
+// nothing; new files count at their full size.
+func (b *BudgetTracker) Observe(snap1, snap2 *types.Snapshot) []types.BudgetBreach {
+	growth := make(map[string]int64)
+	for path, info2 := range snap2.Files {
+		if info2.IsDir {
+			continue
+		}
+		delta := info2.Size
+		if info1, ok := snap1.Files[path]; ok {
+			delta -= info1.Size
+		}
+		if delta > 0 {
+			growth[path] = delta
+		}
+	}
+	b.intervals = append(b.intervals, budgetInterval{end: snap2.Timestamp, growth: growth})
+
+	// Slide the window
+	cutoff := snap2.Timestamp.Add(-b.window)
+	keep := 0
+	for keep < len(b.intervals) && !b.intervals[keep].end.After(cutoff) {
+		keep++
+	}
+	b.intervals = b.intervals[keep:]
+
+	perFile := make(map[string]int64)
+	var total int64
+	for _, iv := range b.intervals {
+		for path, n := range iv.growth {
+			perFile[path] += n
+			total += n
+		}
+	}
+
+	var breaches []types.BudgetBreach
+	if b.fileBudget > 0 {
+		for path, n := range perFile {
+			if n > b.fileBudget {
+				breaches = append(breaches, types.BudgetBreach{
+					Path:        path,
+					GrowthBytes: n,
+					BudgetBytes: b.fileBudget,
+					Window:      b.window,
+				})
+			}
+		}
+	}
+	if b.totalBudget > 0 && total > b.totalBudget {
+		breaches = append(breaches, types.BudgetBreach{
+			GrowthBytes: total,
+			BudgetBytes: b.totalBudget,
+			Window:      b.window,
+		})
+	}
+
+	sort.Slice(breaches, func(i, j int) bool {
+		if breaches[i].GrowthBytes != breaches[j].GrowthBytes {
+			return breaches[i].GrowthBytes > breaches[j].GrowthBytes
+		}
+		return breaches[i].Path < breaches[j].Path
+	})
+
+	return breaches
+}
@@ -0,0 +1,106 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// DefaultChurnRate is the creation rate, in files per second, at which a
+// directory is reported as a churn hotspot.
+const DefaultChurnRate = 10.0
+
+// DetectChurn counts per-directory file creations and deletions across
+// snapshot history (oldest first) and returns directories whose creation
+// rate reaches the configured churn rate, highest first. A path whose inode
+// changed counts as deleted and recreated. Files that live and die entirely
+// between two snapshots can't be seen, so rates are a lower bound.
+func (s *Scanner) DetectChurn(history []*types.Snapshot) []types.ChurnHotspot {
+	threshold := s.config.ChurnRate
+	if threshold <= 0 {
+		threshold = DefaultChurnRate
+	}
+
+	var snaps []*types.Snapshot
+	for _, snap := range history {
+		if snap != nil {
+			snaps = append(snaps, snap)
+		}
+	}
+	if len(snaps) < 2 {
+		return nil
+	}
+
+	first, last := snaps[0], snaps[len(snaps)-1]
+	elapsed := last.Timestamp.Sub(first.Timestamp).Seconds()
+	if elapsed <= 0 {
+		return nil
+	}
+
+	dirs := make(map[string]*types.ChurnHotspot)
+	dir := func(path string) *types.ChurnHotspot {
+		d := filepath.Dir(path)
+		h, ok := dirs[d]
+		if !ok {
+			h = &types.ChurnHotspot{Dir: d}
+			dirs[d] = h
+		}
+		return h
+	}
+
+	for i := 1; i < len(snaps); i++ {
+		prev, cur := snaps[i-1], snaps[i]
+		for path, info := range cur.Files {
+			if info.IsDir {
+				continue
+			}
+			old, ok := prev.Files[path]
+			switch {
+			case !ok:
+				dir(path).Creations++
+			case old.Inode != 0 && info.Inode != 0 && old.Inode != info.Inode:
+				dir(path).Creations++
+				dir(path).Deletions++
+			}
+		}
+		for path, info := range prev.Files {
+			if info.IsDir {
+				continue
+			}
+			if _, ok := cur.Files[path]; !ok {
+				dir(path).Deletions++
+			}
+		}
+	}
+
+	before, after := dirSizes(first), dirSizes(last)
+
+	var hotspots []types.ChurnHotspot
+	for _, h := range dirs {
+		h.CreationRate = float64(h.Creations) / elapsed
+		h.DeletionRate = float64(h.Deletions) / elapsed
+		if h.CreationRate < threshold {
+			continue
+		}
+		h.NetBytes = after[h.Dir] - before[h.Dir]
+		hotspots = append(hotspots, *h)
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		return hotspots[i].CreationRate > hotspots[j].CreationRate
+	})
+
+	return hotspots
+}
+
+// dirSizes sums file sizes per containing directory.
+func dirSizes(snap *types.Snapshot) map[string]int64 {
+	sizes := make(map[string]int64)
+	for path, info := range snap.Files {
+		if !info.IsDir {
+			sizes[filepath.Dir(path)] += info.Size
+		}
+	}
+	return sizes
+}
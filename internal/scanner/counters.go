@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// ScanCounters walks the configured paths like TakeSnapshot but keeps only
+// running totals, so memory use doesn't grow with the number of files.
+// Symlinks are not de-duplicated against their targets, since that needs
+// the full file map, and MaxFiles doesn't apply.
+func (s *Scanner) ScanCounters(ctx context.Context) (*types.SnapshotCounters, error) {
+	counters := &types.SnapshotCounters{Timestamp: time.Now()}
+
+	for _, basePath := range s.basePaths() {
+		err := walkTree(ctx, s.config, basePath, 0, func(path string, _ os.FileMode) error {
+			info, err := s.statFile(path)
+			if err != nil || info.IsDir {
+				return nil
+			}
+			counters.TotalSize += info.Size
+			counters.FileCount++
+			if info.Size > counters.LargestFile {
+				counters.LargestFile = info.Size
+				counters.LargestPath = info.Path
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return counters, nil
+}
+
+// CounterGrowthRate returns the rate, in bytes per second, at which the
+// total size changed between two counter snapshots. Shrinking yields a
+// negative rate.
+func CounterGrowthRate(c1, c2 *types.SnapshotCounters) float64 {
+	interval := c2.Timestamp.Sub(c1.Timestamp)
+	if interval <= 0 {
+		interval = time.Second // Prevent division by zero
+	}
+	return float64(c2.TotalSize-c1.TotalSize) / interval.Seconds()
+}
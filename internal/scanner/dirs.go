@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+	"github.com/thiruk/logmonster/pkg/util"
+)
+
+// dirSizer accumulates the aggregate size of every directory from each
+// file's parent up to its base path.
+type dirSizer struct {
+	bases []string
+	sizes map[string]int64
+}
+
+func newDirSizer(bases []string) *dirSizer {
+	return &dirSizer{bases: bases, sizes: make(map[string]int64)}
+}
+
+// add counts a file towards its directories.
+func (d *dirSizer) add(info types.FileInfo) {
+	if info.IsDir {
+		return
+	}
+	base := ""
+	for _, b := range d.bases {
+		if pathHasPrefix(info.Path, b) && len(b) > len(base) {
+			base = b
+		}
+	}
+	if base == "" {
+		return // e.g. a symlink resolved outside the scanned trees
+	}
+
+	for dir := filepath.Dir(info.Path); ; dir = filepath.Dir(dir) {
+		d.sizes[dir] += info.Size
+		if dir == base || dir == filepath.Dir(dir) {
+			return
+		}
+	}
+}
+
+// recordDirSizes fills snapshot.Dirs from the files in the snapshot.
+func (s *Scanner) recordDirSizes(snapshot *types.Snapshot) {
+	d := newDirSizer(s.basePaths())
+	for _, info := range snapshot.Files {
+		d.add(info)
+	}
+	snapshot.Dirs = d.sizes
+}
+
+// dirGrowth returns directories whose growth is not explained by reported
+// files or deeper reported directories, with that residual growth as
+// GrowthBytes and GrowthRate. claimed holds, per directory, the
+// growth already attributed to files reported beneath it and is updated as
+// directories are reported, so a single large file doesn't flag every
+// ancestor as well. With warmup set, directories new in snap2 are skipped
+// like new files.
+func dirGrowth(snap1, snap2 *types.Snapshot, interval time.Duration, minBytes int64, minRate float64, claimed map[string]int64, warmup bool) []types.FileGrowth {
+	if snap1.Dirs == nil || snap2.Dirs == nil {
+		return nil
+	}
+
+	// Deepest first, so residual growth is attributed as low as possible
+	dirs := make([]string, 0, len(snap2.Dirs))
+	for dir := range snap2.Dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		di := strings.Count(dirs[i], string(filepath.Separator))
+		dj := strings.Count(dirs[j], string(filepath.Separator))
+		if di != dj {
+			return di > dj
+		}
+		return dirs[i] < dirs[j]
+	})
+
+	var growing []types.FileGrowth
+	for _, dir := range dirs {
+		size2 := snap2.Dirs[dir]
+		size1, existed := snap1.Dirs[dir]
+		if warmup && !existed {
+			continue
+		}
+		residual := size2 - size1 - claimed[dir]
+		rate := float64(residual) / interval.Seconds()
+		if !exceeds(residual, rate, minBytes, minRate) {
+			continue
+		}
+
+		growing = append(growing, types.FileGrowth{
+			Path:        dir,
+			IsDir:       true,
+			InitialSize: size1,
+			FinalSize:   size2,
+			GrowthBytes: residual,
+			GrowthRate:  rate,
+			Interval:    interval,
+			Reason:      "grew " + util.FormatBytesWithSign(residual) + " outside reported files",
+		})
+		claimAncestors(claimed, dir, residual)
+	}
+
+	return growing
+}
+
+// claimAncestors attributes growth at path to every directory above it.
+func claimAncestors(claimed map[string]int64, path string, growth int64) {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		claimed[dir] += growth
+		if dir == filepath.Dir(dir) {
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestTakeSnapshotDirSizesBeforeCap(t *testing.T) {
+	config := DefaultConfig()
+	config.Paths = []string{"/var/log"}
+	config.FS = FromIOFS(fstest.MapFS{
+		"var/log/app/big.log":   {Data: make([]byte, 100)},
+		"var/log/app/small.log": {Data: make([]byte, 10)},
+		"var/log/db/db.log":     {Data: make([]byte, 5)},
+	})
+	config.TrackDirs = true
+	config.MaxFiles = 1
+
+	snapshot, err := New(config).TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Truncated || len(snapshot.Files) != 1 {
+		t.Fatalf("snapshot kept %d files, truncated %v; want 1 file, truncated", len(snapshot.Files), snapshot.Truncated)
+	}
+	want := map[string]int64{"/var/log": 115, "/var/log/app": 110, "/var/log/db": 5}
+	for dir, size := range want {
+		if got := snapshot.Dirs[dir]; got != size {
+			t.Errorf("Dirs[%s] = %d, want %d", dir, got, size)
+		}
+	}
+}
+
+func TestDirGrowthReportsResidual(t *testing.T) {
+	snap1 := snapshotOf(0, nil)
+	snap1.Dirs = map[string]int64{"/var/log": 1000, "/var/log/app": 600}
+	snap2 := snapshotOf(10, nil)
+	snap2.Dirs = map[string]int64{"/var/log": 3000, "/var/log/app": 2400}
+
+	// A reported file in /var/log/app accounts for 1000 of its 1800 bytes
+	claimed := map[string]int64{"/var/log/app": 1000, "/var/log": 1000, "/var": 1000, "/": 1000}
+
+	growing := dirGrowth(snap1, snap2, 10*time.Second, 100, 0, claimed, false)
+	if len(growing) != 2 {
+		t.Fatalf("got %d growing directories, want 2", len(growing))
+	}
+	for i, want := range []struct {
+		path   string
+		growth int64
+	}{
+		{"/var/log/app", 800},
+		{"/var/log", 200},
+	} {
+		g := growing[i]
+		if g.Path != want.path || g.GrowthBytes != want.growth {
+			t.Errorf("growing[%d] = %s +%d, want %s +%d", i, g.Path, g.GrowthBytes, want.path, want.growth)
+		}
+		if g.GrowthRate != float64(want.growth)/10 {
+			t.Errorf("%s rate = %v, want %v", g.Path, g.GrowthRate, float64(want.growth)/10)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// ErrFanotifyUnavailable is returned when fanotify cannot be used, either
+// because the platform lacks it or the process lacks CAP_SYS_ADMIN.
+var ErrFanotifyUnavailable = errors.New("fanotify not available")
+
+// GrowthScanner is implemented by anything that can produce a scan result.
+type GrowthScanner interface {
+	Scan(ctx context.Context) (*types.ScanResult, error)
+}
+
+// NewMountScanner returns a FanotifyWatcher when fanotify is permitted and
+// falls back to a polling Scanner otherwise.
+func NewMountScanner(config Config) GrowthScanner {
+	if w, err := NewFanotifyWatcher(config); err == nil {
+		return w
+	}
+	return New(config)
+}
@@ -0,0 +1,243 @@
+//go:build linux
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unsafe"
+
+	"github.com/thiruk/logmonster/pkg/types"
+	"golang.org/x/sys/unix"
+)
+
+// FanotifyWatcher monitors entire mounts for writes using fanotify.
+// It requires CAP_SYS_ADMIN.
+//
+// A write event arrives after the write, so sizes at the first event would
+// miss it. Instead the first Scan takes a baseline snapshot once its marks
+// are in place, and every Scan carries the final sizes of the files written
+// to forward as the next window's starting sizes. Scan must not be called
+// concurrently.
+type FanotifyWatcher struct {
+	scanner *Scanner
+	sizes   map[string]int64 // size of every known file at the end of the last window
+}
+
+// NewFanotifyWatcher creates a watcher for the mounts containing the
+// configured paths. It returns ErrFanotifyUnavailable if fanotify is not
+// permitted.
+func NewFanotifyWatcher(config Config) (*FanotifyWatcher, error) {
+	fd, err := fanotifyInit()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFanotifyUnavailable, err)
+	}
+	unix.Close(fd)
+	return &FanotifyWatcher{scanner: New(config)}, nil
+}
+
+func fanotifyInit() (int, error) {
+	return unix.FanotifyInit(
+		unix.FAN_CLASS_NOTIF|unix.FAN_CLOEXEC|unix.FAN_NONBLOCK,
+		unix.O_RDONLY|unix.O_LARGEFILE|unix.O_CLOEXEC,
+	)
+}
+
+// Scan collects write events for one interval and reports files whose
+// growth crosses the threshold.
+func (w *FanotifyWatcher) Scan(ctx context.Context) (*types.ScanResult, error) {
+	config := w.scanner.config
+
+	fd, err := fanotifyInit()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFanotifyUnavailable, err)
+	}
+	defer unix.Close(fd)
+
+	for _, path := range w.scanner.basePaths() {
+		err := unix.FanotifyMark(fd, unix.FAN_MARK_ADD|unix.FAN_MARK_MOUNT,
+			unix.FAN_MODIFY|unix.FAN_CLOSE_WRITE, unix.AT_FDCWD, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to mark mount for %s: %w", path, err)
+		}
+	}
+
+	// Marks are in place, so any write from here on raises an event
+	if w.sizes == nil {
+		if err := w.baseline(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &types.ScanResult{
+		StartTime: time.Now(),
+		Paths:     config.Paths,
+		Interval:  config.Interval,
+	}
+
+	written := make(map[string]bool)
+	if err := w.collect(ctx, fd, written); err != nil {
+		return nil, err
+	}
+	result.EndTime = time.Now()
+
+	interval := result.EndTime.Sub(result.StartTime)
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	w.measure(result, written, interval)
+	return result, nil
+}
+
+// baseline records the size of every file in scope from a snapshot.
+func (w *FanotifyWatcher) baseline(ctx context.Context) error {
+	snapshot, err := w.scanner.TakeSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	w.sizes = make(map[string]int64, len(snapshot.Files))
+	for path, info := range snapshot.Files {
+		if !info.IsDir {
+			w.sizes[path] = info.Size
+		}
+	}
+	return nil
+}
+
+// measure adds the files written to over interval whose growth since their
+// last known size is flagged to result, and records their current sizes
+// for the next window. A file with no known size is new.
+func (w *FanotifyWatcher) measure(result *types.ScanResult, written map[string]bool, interval time.Duration) {
+	calc := w.scanner.calculator()
+	mounts := readMountTable()
+	for path := range written {
+		info, err := os.Stat(path)
+		if err != nil {
+			delete(w.sizes, path) // Deleted or renamed during the window
+			continue
+		}
+
+		var info1 types.FileInfo
+		if size, ok := w.sizes[path]; ok {
+			info1 = types.FileInfo{Path: path, Size: size}
+		}
+		info2 := types.FileInfo{Path: path, Size: info.Size(), FilesystemType: mounts.typeOf(path)}
+		w.sizes[path] = info2.Size
+
+		g, ok := calc.Evaluate(path, info1, info2, interval)
+		if !ok {
+			continue
+		}
+		result.GrowingFiles = append(result.GrowingFiles, g)
+		result.TotalGrowth += g.GrowthBytes
+	}
+
+	sort.Slice(result.GrowingFiles, func(i, j int) bool {
+		return result.GrowingFiles[i].GrowthRate > result.GrowingFiles[j].GrowthRate
+	})
+}
+
+// collect reads fanotify events until the interval elapses or ctx is done.
+func (w *FanotifyWatcher) collect(ctx context.Context, fd int, written map[string]bool) error {
+	buf := make([]byte, 4096*unix.FAN_EVENT_METADATA_LEN)
+	deadline := time.Now().Add(w.scanner.config.Interval)
+
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		timeout := 100 * time.Millisecond
+		if remaining < timeout {
+			timeout = remaining
+		}
+
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		n, err := unix.Poll(fds, int(timeout.Milliseconds()))
+		if err != nil {
+			if err == unix.EINTR {
+				continue
+			}
+			return err
+		}
+		if n == 0 {
+			continue
+		}
+
+		nr, err := unix.Read(fd, buf)
+		if err != nil {
+			if err == unix.EAGAIN || err == unix.EINTR {
+				continue
+			}
+			return err
+		}
+
+		for off := 0; off+unix.FAN_EVENT_METADATA_LEN <= nr; {
+			meta := (*unix.FanotifyEventMetadata)(unsafe.Pointer(&buf[off]))
+			if meta.Vers != unix.FANOTIFY_METADATA_VERSION {
+				return fmt.Errorf("unsupported fanotify metadata version %d", meta.Vers)
+			}
+			if meta.Fd >= 0 {
+				w.record(int(meta.Fd), written)
+				unix.Close(int(meta.Fd))
+			}
+			if meta.Event_len == 0 {
+				break
+			}
+			off += int(meta.Event_len)
+		}
+	}
+}
+
+// record notes the file behind an event descriptor as written to if it is
+// a regular file in scope.
+func (w *FanotifyWatcher) record(eventFd int, written map[string]bool) {
+	path, err := os.Readlink(fmt.Sprintf("/proc/self/fd/%d", eventFd))
+	if err != nil {
+		return
+	}
+	if written[path] {
+		return
+	}
+	if !w.inScope(path) {
+		return
+	}
+
+	var st unix.Stat_t
+	if err := unix.Fstat(eventFd, &st); err != nil {
+		return
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFREG {
+		return
+	}
+
+	written[path] = true
+}
+
+// inScope reports whether path lies under a configured path and is not excluded.
+func (w *FanotifyWatcher) inScope(path string) bool {
+	config := w.scanner.config
+	if matchExclude(config.ExcludePatterns, path) || matchExcludeDir(config.ExcludeDirs, path) {
+		return false
+	}
+	for _, base := range w.scanner.basePaths() {
+		base = filepath.Clean(base)
+		if path == base || strings.HasPrefix(path, base+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+//go:build linux
+
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// appendBytes appends n bytes to path in a single write.
+func appendBytes(t *testing.T, path string, n int) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write(make([]byte, n)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFanotifyWatcherMeasure(t *testing.T) {
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app.log")
+	appendBytes(t, app, 100)
+
+	config := DefaultConfig()
+	config.Paths = []string{dir}
+	config.ThresholdBytes = 1
+	w := &FanotifyWatcher{scanner: New(config)}
+	if err := w.baseline(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	growth := func(written ...string) map[string]int64 {
+		t.Helper()
+		set := make(map[string]bool)
+		for _, path := range written {
+			set[path] = true
+		}
+		result := &types.ScanResult{}
+		w.measure(result, set, time.Second)
+		got := make(map[string]int64)
+		for _, g := range result.GrowingFiles {
+			got[g.Path] = g.GrowthBytes
+		}
+		return got
+	}
+
+	// A single write is counted in full
+	appendBytes(t, app, 5000)
+	if got := growth(app)[app]; got != 5000 {
+		t.Errorf("growth after one write = %d, want 5000", got)
+	}
+
+	// The next window starts from this window's final size, and a file
+	// created during it counts as new
+	appendBytes(t, app, 300)
+	created := filepath.Join(dir, "new.log")
+	appendBytes(t, created, 700)
+	got := growth(app, created)
+	if got[app] != 300 || got[created] != 700 {
+		t.Errorf("growth in second window = %v, want %s +300 and %s +700", got, app, created)
+	}
+}
+
+func TestFanotifyWatcherCountsFirstWrite(t *testing.T) {
+	dir := t.TempDir()
+	app := filepath.Join(dir, "app.log")
+	appendBytes(t, app, 100)
+
+	config := DefaultConfig()
+	config.Paths = []string{dir}
+	config.ThresholdBytes = 1
+	config.Interval = time.Second
+	w, err := NewFanotifyWatcher(config)
+	if err != nil {
+		t.Skipf("fanotify unavailable: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		time.Sleep(300 * time.Millisecond)
+		f, err := os.OpenFile(app, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return
+		}
+		f.Write(make([]byte, 5000))
+		f.Close()
+	}()
+
+	result, err := w.Scan(context.Background())
+	<-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.GrowingFiles) != 1 || result.GrowingFiles[0].GrowthBytes != 5000 {
+		t.Fatalf("growing files = %+v, want %s grown by 5000", result.GrowingFiles, app)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"context"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// FanotifyWatcher is only supported on Linux.
+type FanotifyWatcher struct{}
+
+// NewFanotifyWatcher always returns ErrFanotifyUnavailable on this platform.
+func NewFanotifyWatcher(config Config) (*FanotifyWatcher, error) {
+	return nil, ErrFanotifyUnavailable
+}
+
+// Scan always returns ErrFanotifyUnavailable on this platform.
+func (w *FanotifyWatcher) Scan(ctx context.Context) (*types.ScanResult, error) {
+	return nil, ErrFanotifyUnavailable
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// FS is the filesystem a Scanner or Walker reads. Names are host paths as
+// given in Config.Paths. ReadDir must return entries sorted by name, as
+// os.ReadDir does, so walks stay deterministic.
+//
+// Glob expansion of Config.Paths, ResolveSymlinks and the disk usage checks
+// always use the host filesystem. Mount usage is only recorded in snapshots
+// of the host filesystem.
+type FS interface {
+	ReadDir(name string) ([]os.DirEntry, error)
+	Stat(name string) (os.FileInfo, error)
+}
+
+// OSFS is the host filesystem, used when Config.FS is nil.
+var OSFS FS = osFS{}
+
+// osFS falls back to openat relative to a parent directory for paths the
+// kernel rejects as too long, so deeply nested files aren't silently lost.
+type osFS struct{}
+
+func (osFS) ReadDir(name string) ([]os.DirEntry, error) {
+	entries, err := os.ReadDir(name)
+	if isNameTooLong(err) {
+		return readDirLong(name)
+	}
+	return entries, err
+}
+
+func (osFS) Stat(name string) (os.FileInfo, error) {
+	info, err := os.Stat(name)
+	if isNameTooLong(err) {
+		return statLong(name)
+	}
+	return info, err
+}
+
+// isNameTooLong reports whether err is ENAMETOOLONG.
+func isNameTooLong(err error) bool {
+	return errors.Is(err, syscall.ENAMETOOLONG)
+}
+
+// FromIOFS adapts an io/fs filesystem such as fstest.MapFS. The leading
+// separator is stripped from names, so "/var/log" reads "var/log" in fsys.
+func FromIOFS(fsys fs.FS) FS {
+	return ioFS{fsys}
+}
+
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) ReadDir(name string) ([]os.DirEntry, error) {
+	return fs.ReadDir(f.fsys, ioName(name))
+}
+
+func (f ioFS) Stat(name string) (os.FileInfo, error) {
+	return fs.Stat(f.fsys, ioName(name))
+}
+
+// ioName converts a host path into an io/fs name.
+func ioName(name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
+
+// fs returns the filesystem the config walks.
+func (c Config) fs() FS {
+	if c.FS == nil {
+		return OSFS
+	}
+	return c.FS
+}
@@ -0,0 +1,222 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// flakyFS fails the first fails Stat calls for each name with err.
+type flakyFS struct {
+	FS
+	err   error
+	fails int
+	calls map[string]int
+}
+
+func (f *flakyFS) Stat(name string) (os.FileInfo, error) {
+	f.calls[name]++
+	if f.calls[name] <= f.fails {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: f.err}
+	}
+	return f.FS.Stat(name)
+}
+
+func TestStatRetry(t *testing.T) {
+	base := FromIOFS(fstest.MapFS{"var/log/app.log": {Data: []byte("hello")}})
+
+	tests := []struct {
+		name      string
+		err       error
+		fails     int
+		wantErr   bool
+		wantCalls int
+	}{
+		{"no errors", syscall.EINTR, 0, false, 1},
+		{"EINTR retried", syscall.EINTR, 2, false, 3},
+		{"EAGAIN retried", syscall.EAGAIN, 1, false, 2},
+		{"EINTR gives up", syscall.EINTR, maxStatRetries + 1, true, maxStatRetries + 1},
+		{"EACCES not retried", syscall.EACCES, 1, true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := &flakyFS{FS: base, err: tt.err, fails: tt.fails, calls: make(map[string]int)}
+
+			info, err := statRetry(fsys, "/var/log/app.log")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("statRetry error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && info.Size() != 5 {
+				t.Errorf("size = %d, want 5", info.Size())
+			}
+			if got := fsys.calls["/var/log/app.log"]; got != tt.wantCalls {
+				t.Errorf("Stat called %d times, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestTakeSnapshotRetriesEINTR(t *testing.T) {
+	base := FromIOFS(fstest.MapFS{
+		"var/log/a.log": {Data: []byte("a")},
+		"var/log/b.log": {Data: []byte("bb")},
+	})
+
+	config := DefaultConfig()
+	config.Paths = []string{"/var/log"}
+	config.FS = &flakyFS{FS: base, err: syscall.EINTR, fails: 2, calls: make(map[string]int)}
+	config.WorkerCount = 1
+
+	snapshot, err := New(config).TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.FileCount != 2 || snapshot.TotalSize != 3 {
+		t.Errorf("snapshot has %d files of %d bytes, want 2 files of 3 bytes", snapshot.FileCount, snapshot.TotalSize)
+	}
+}
+
+func TestTakeSnapshotMapFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"srv/log/app.log":           {Data: []byte("12345")},
+		"srv/log/app.log.gz":        {Data: []byte("gz")},
+		"srv/log/nginx/access.log":  {Data: []byte("abc")},
+		"srv/log/nginx/error.log":   {Data: []byte("e")},
+		"srv/log/cache/blob":        {Data: []byte("cached")},
+		"srv/log/a/b/c/deep.log":    {Data: []byte("deep")},
+		"srv/log/empty":             {Mode: fs.ModeDir},
+		"srv/other/not-scanned.log": {Data: []byte("x")},
+	}
+
+	tests := []struct {
+		name   string
+		config func(c *Config)
+		want   map[string]int64
+	}{
+		{"everything", func(c *Config) {}, map[string]int64{
+			"/srv/log/app.log":          5,
+			"/srv/log/app.log.gz":       2,
+			"/srv/log/nginx/access.log": 3,
+			"/srv/log/nginx/error.log":  1,
+			"/srv/log/cache/blob":       6,
+			"/srv/log/a/b/c/deep.log":   4,
+		}},
+		{"exclude patterns and dirs", func(c *Config) {
+			c.ExcludePatterns = []string{"*.gz"}
+			c.ExcludeDirs = []string{"/srv/log/cache"}
+		}, map[string]int64{
+			"/srv/log/app.log":          5,
+			"/srv/log/nginx/access.log": 3,
+			"/srv/log/nginx/error.log":  1,
+			"/srv/log/a/b/c/deep.log":   4,
+		}},
+		{"max depth", func(c *Config) { c.MaxDepth = 1 }, map[string]int64{
+			"/srv/log/app.log":          5,
+			"/srv/log/app.log.gz":       2,
+			"/srv/log/nginx/access.log": 3,
+			"/srv/log/nginx/error.log":  1,
+			"/srv/log/cache/blob":       6,
+		}},
+		{"several paths", func(c *Config) {
+			c.Paths = []string{"/srv/log/nginx", "/srv/other"}
+		}, map[string]int64{
+			"/srv/log/nginx/access.log":  3,
+			"/srv/log/nginx/error.log":   1,
+			"/srv/other/not-scanned.log": 1,
+		}},
+		{"missing path", func(c *Config) { c.Paths = []string{"/srv/none"} }, map[string]int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Paths = []string{"/srv/log"}
+			config.FS = FromIOFS(fsys)
+			tt.config(&config)
+
+			snapshot, err := New(config).TakeSnapshot(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]int64, len(snapshot.Files))
+			var total int64
+			for path, info := range snapshot.Files {
+				got[path] = info.Size
+				total += info.Size
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("snapshot files = %v, want %v", got, tt.want)
+			}
+			if snapshot.FileCount != len(tt.want) || snapshot.TotalSize != total {
+				t.Errorf("snapshot totals = %d files, %d bytes; want %d files, %d bytes",
+					snapshot.FileCount, snapshot.TotalSize, len(tt.want), total)
+			}
+		})
+	}
+}
+
+func TestTakeSnapshotMapFSSkipsMounts(t *testing.T) {
+	// The path exists on the host too, so only the FS check keeps host
+	// mounts out of the snapshot
+	dir := t.TempDir()
+	config := DefaultConfig()
+	config.Paths = []string{dir}
+	config.FS = FromIOFS(fstest.MapFS{
+		strings.TrimPrefix(dir, "/") + "/app.log": {Data: []byte("hello")},
+	})
+
+	snapshot, err := New(config).TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.FileCount != 1 {
+		t.Fatalf("snapshot has %d files, want 1", snapshot.FileCount)
+	}
+	if len(snapshot.Mounts) != 0 {
+		t.Errorf("snapshot mounts = %v, want none for an in-memory FS", snapshot.Mounts)
+	}
+}
+
+func TestSnapshotsMapFSGrowth(t *testing.T) {
+	fsys := fstest.MapFS{
+		"srv/log/app.log":    {Data: make([]byte, 100)},
+		"srv/log/steady.log": {Data: make([]byte, 100)},
+	}
+
+	config := DefaultConfig()
+	config.Paths = []string{"/srv/log"}
+	config.FS = FromIOFS(fsys)
+	config.ThresholdBytes = 500
+	s := New(config)
+
+	snap1, err := s.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys["srv/log/app.log"] = &fstest.MapFile{Data: make([]byte, 1000)}
+	fsys["srv/log/new.log"] = &fstest.MapFile{Data: make([]byte, 600)}
+	snap2, err := s.TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap2.Timestamp = snap1.Timestamp.Add(10 * time.Second)
+
+	var got []string
+	for _, g := range s.CalculateGrowth(snap1, snap2, false) {
+		got = append(got, fmt.Sprintf("%s +%d", g.Path, g.GrowthBytes))
+	}
+	sort.Strings(got)
+	if want := []string{"/srv/log/app.log +900", "/srv/log/new.log +600"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("growing = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,46 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// mountTable maps mount points to filesystem types.
+type mountTable map[string]string
+
+// typeOf returns the filesystem type of the mount holding path: that of
+// the deepest mount point among path and its ancestors. It is empty if
+// unknown.
+func (t mountTable) typeOf(path string) string {
+	if len(t) == 0 {
+		return ""
+	}
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if typ, ok := t[dir]; ok {
+			return typ
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
+}
+
+// snapshotMounts returns the filesystem types recorded in a snapshot's
+// mount usage.
+func snapshotMounts(snapshot *types.Snapshot) mountTable {
+	table := make(mountTable, len(snapshot.Mounts))
+	for mount, usage := range snapshot.Mounts {
+		table[mount] = usage.FilesystemType
+	}
+	return table
+}
+
+// pathHasPrefix reports whether path is dir or lies beneath it.
+func pathHasPrefix(path, dir string) bool {
+	if dir == string(filepath.Separator) || path == dir {
+		return true
+	}
+	return strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package scanner
+
+// readMountTable is only implemented on Linux; elsewhere filesystem types
+// are left empty.
+func readMountTable() mountTable {
+	return nil
+}
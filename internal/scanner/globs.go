@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// globCacheTTL is how long glob expansions of scan paths are reused, so that
+// back-to-back snapshots don't each pay for globbing.
+const globCacheTTL = 10 * time.Second
+
+// globCache holds the most recent expansion of the configured paths.
+type globCache struct {
+	mu      sync.Mutex
+	paths   []string
+	expires time.Time
+}
+
+// hasGlob reports whether path contains glob metacharacters.
+func hasGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// basePaths returns the configured paths with glob patterns expanded, so a
+// pattern like /var/log/app/2024-* picks up directories created after
+// startup. Expansions are cached for globCacheTTL.
+func (s *Scanner) basePaths() []string {
+	globbed := false
+	for _, p := range s.config.Paths {
+		if hasGlob(p) {
+			globbed = true
+			break
+		}
+	}
+	if !globbed {
+		return s.config.Paths
+	}
+
+	s.globs.mu.Lock()
+	defer s.globs.mu.Unlock()
+
+	now := time.Now()
+	if s.globs.paths != nil && now.Before(s.globs.expires) {
+		return s.globs.paths
+	}
+
+	seen := make(map[string]bool)
+	paths := []string{}
+	for _, p := range s.config.Paths {
+		matches := []string{p}
+		if hasGlob(p) {
+			var err error
+			matches, err = filepath.Glob(p)
+			if err != nil {
+				continue // Malformed pattern
+			}
+		}
+		for _, m := range matches {
+			m = filepath.Clean(m)
+			if !seen[m] {
+				seen[m] = true
+				paths = append(paths, m)
+			}
+		}
+	}
+
+	s.globs.paths = paths
+	s.globs.expires = now.Add(globCacheTTL)
+	return paths
+}
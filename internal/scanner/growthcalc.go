@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// ThresholdStrategy decides whether a file's change between two snapshots
+// is flagged. For a file that is new in the second snapshot, info1 is the
+// zero FileInfo.
+type ThresholdStrategy interface {
+	ShouldFlag(info1, info2 types.FileInfo, interval time.Duration) bool
+}
+
+// Reasoner is implemented by a ThresholdStrategy that explains its own
+// decisions. GrowthCalculator calls FlagGrowth instead of ShouldFlag with
+// the measured growth g, which it fills in with a Reason, and details such
+// as ZScore, when it flags the file. For a new file info1 is the zero
+// FileInfo.
+type Reasoner interface {
+	FlagGrowth(g *types.FileGrowth, info1, info2 types.FileInfo) bool
+}
+
+// forgetter is implemented by a strategy that keeps per-file history, so
+// calculateGrowth can drop files missing from a complete snapshot.
+type forgetter interface {
+	forget(snapshot *types.Snapshot)
+}
+
+// FixedBytes flags files that grew by at least that many bytes.
+type FixedBytes int64
+
+// ShouldFlag implements ThresholdStrategy.
+func (n FixedBytes) ShouldFlag(info1, info2 types.FileInfo, _ time.Duration) bool {
+	return info2.Size-info1.Size >= int64(n)
+}
+
+// RateThreshold flags files whose growth reaches Bytes and whose growth rate
+// reaches BytesPerSec. It is what a Scanner applies by default.
+type RateThreshold struct {
+	Bytes       int64
+	BytesPerSec float64
+}
+
+// ShouldFlag implements ThresholdStrategy.
+func (t RateThreshold) ShouldFlag(info1, info2 types.FileInfo, interval time.Duration) bool {
+	growth := info2.Size - info1.Size
+	return exceeds(growth, float64(growth)/interval.Seconds(), t.Bytes, t.BytesPerSec)
+}
+
+// FlagGrowth implements Reasoner, naming the rate threshold it crossed.
+func (t RateThreshold) FlagGrowth(g *types.FileGrowth, info1, _ types.FileInfo) bool {
+	if !exceeds(g.GrowthBytes, g.GrowthRate, t.Bytes, t.BytesPerSec) {
+		return false
+	}
+	g.Reason = growthReason(g.GrowthBytes, g.GrowthRate, t.BytesPerSec, info1 == types.FileInfo{})
+	return true
+}
+
+// GrowthCalculator measures file growth between snapshots and flags files
+// with its Strategy. Scanner.CalculateGrowth and CompareSnapshots both use
+// it, so every strategy sees files the same way.
+type GrowthCalculator struct {
+	Strategy  ThresholdStrategy
+	IgnoreNew bool // skip files missing from the first snapshot
+}
+
+// Evaluate measures one file's growth over interval and reports whether the
+// strategy flags it, with the reason in the returned FileGrowth. Pass the
+// zero FileInfo as info1 for a new file.
+func (c GrowthCalculator) Evaluate(path string, info1, info2 types.FileInfo, interval time.Duration) (types.FileGrowth, bool) {
+	growth := info2.Size - info1.Size
+	g := types.FileGrowth{
+		Path:           path,
+		InitialSize:    info1.Size,
+		FinalSize:      info2.Size,
+		GrowthBytes:    growth,
+		GrowthRate:     float64(growth) / interval.Seconds(),
+		Interval:       interval,
+		SelfGenerated:  info2.SelfGenerated,
+		FilesystemType: info2.FilesystemType,
+	}
+	if r, ok := c.Strategy.(Reasoner); ok {
+		return g, r.FlagGrowth(&g, info1, info2)
+	}
+	if !c.Strategy.ShouldFlag(info1, info2, interval) {
+		return g, false
+	}
+	g.Reason = growthReason(growth, g.GrowthRate, 0, info1 == types.FileInfo{})
+	return g, true
+}
+
+// Calculate returns the flagged files of snap2, sorted by growth rate
+// descending. Directory entries are ignored. If either snapshot is
+// Truncated, files missing from snap1 are not treated as new, since the
+// MaxFiles cap may have dropped them.
+func (c GrowthCalculator) Calculate(snap1, snap2 *types.Snapshot) []types.FileGrowth {
+	interval := snapshotInterval(snap1, snap2)
+	ignoreNew := c.IgnoreNew || snap1.Truncated || snap2.Truncated
+
+	var flagged []types.FileGrowth
+	for path, info2 := range snap2.Files {
+		if info2.IsDir {
+			continue
+		}
+		info1, exists := snap1.Files[path]
+		if !exists && ignoreNew {
+			continue
+		}
+		if g, ok := c.Evaluate(path, info1, info2, interval); ok {
+			flagged = append(flagged, g)
+		}
+	}
+
+	sort.Slice(flagged, func(i, j int) bool {
+		return flagged[i].GrowthRate > flagged[j].GrowthRate
+	})
+	return flagged
+}
+
+// snapshotInterval returns the time between two snapshots, or a second if
+// they are out of order, to keep rates finite.
+func snapshotInterval(snap1, snap2 *types.Snapshot) time.Duration {
+	interval := snap2.Timestamp.Sub(snap1.Timestamp)
+	if interval <= 0 {
+		interval = time.Second // Prevent division by zero
+	}
+	return interval
+}
@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+func TestGrowthCalculatorReasons(t *testing.T) {
+	old := types.FileInfo{Path: "/log/app.log", Size: 1000}
+	grown := types.FileInfo{Path: "/log/app.log", Size: 3000}
+
+	tests := []struct {
+		name     string
+		strategy ThresholdStrategy
+		info1    types.FileInfo
+		want     string
+	}{
+		{"rate threshold", RateThreshold{Bytes: 100, BytesPerSec: 50}, old, "rate 200 B/s exceeds 50 B/s"},
+		{"byte threshold", RateThreshold{Bytes: 100}, old, "grew +2.0 KB"},
+		{"fixed bytes", FixedBytes(100), old, "grew +2.0 KB"},
+		{"new file", RateThreshold{Bytes: 100, BytesPerSec: 50}, types.FileInfo{}, "new file over threshold (2.9 KB)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, ok := GrowthCalculator{Strategy: tt.strategy}.Evaluate(grown.Path, tt.info1, grown, 10*time.Second)
+			if !ok {
+				t.Fatal("not flagged")
+			}
+			if g.Reason != tt.want {
+				t.Errorf("reason = %q, want %q", g.Reason, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselineTrackerSetsZScore(t *testing.T) {
+	s := New(DefaultConfig())
+	s.SetThresholdStrategy(NewBaselineTracker(10, 3))
+
+	size := int64(0)
+	snap := snapshotOf(0, map[string]int64{"/log/app.log": size})
+	var growing []types.FileGrowth
+	for i, step := range []int64{100, 110, 90, 100, 105, 95, 5000} {
+		size += step
+		next := snapshotOf((i+1)*10, map[string]int64{"/log/app.log": size})
+		growing = s.CalculateGrowth(snap, next, false)
+		snap = next
+	}
+
+	if len(growing) != 1 {
+		t.Fatalf("got %d growing files after the spike, want 1", len(growing))
+	}
+	if g := growing[0]; g.ZScore <= 3 || !strings.HasPrefix(g.Reason, "z-score ") {
+		t.Errorf("spike flagged with z-score %.1f and reason %q", g.ZScore, g.Reason)
+	}
+}
+
+// flagAll is a strategy that flags every file.
+type flagAll struct{}
+
+func (flagAll) ShouldFlag(_, _ types.FileInfo, _ time.Duration) bool { return true }
+
+func (flagAll) FlagGrowth(g *types.FileGrowth, _, _ types.FileInfo) bool {
+	g.Reason = "flagged by strategy"
+	return true
+}
+
+func TestStreamingScanUsesStrategy(t *testing.T) {
+	config := DefaultConfig()
+	config.Paths = []string{"/srv/log"}
+	config.FS = FromIOFS(fstest.MapFS{
+		"srv/log/a.log": {Data: []byte("a")},
+		"srv/log/b.log": {Data: []byte("b")},
+	})
+	config.Interval = time.Millisecond
+	s := New(config)
+	s.SetThresholdStrategy(flagAll{})
+
+	result, err := s.StreamingScan(context.Background(), t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.GrowingFiles) != 2 {
+		t.Fatalf("got %d growing files, want both", len(result.GrowingFiles))
+	}
+	for _, g := range result.GrowingFiles {
+		if g.Reason != "flagged by strategy" {
+			t.Errorf("%s reason = %q, want the strategy's", g.Path, g.Reason)
+		}
+	}
+}
+
+func TestBaselineTrackerForgetsVanishedFiles(t *testing.T) {
+	s := New(DefaultConfig())
+	tracker := NewBaselineTracker(10, 3)
+	s.SetThresholdStrategy(tracker)
+
+	snap1 := snapshotOf(0, map[string]int64{"/log/app.log.1": 10, "/log/app.log.2": 10})
+	snap2 := snapshotOf(10, map[string]int64{"/log/app.log.1": 20, "/log/app.log.2": 20})
+	s.CalculateGrowth(snap1, snap2, false)
+
+	// A truncated snapshot may just have dropped a file, so it is kept
+	snap3 := snapshotOf(20, map[string]int64{"/log/app.log.2": 30})
+	snap3.Truncated = true
+	s.CalculateGrowth(snap2, snap3, false)
+	if len(tracker.samples) != 2 {
+		t.Fatalf("tracker holds %d files after a truncated snapshot, want 2", len(tracker.samples))
+	}
+
+	snap4 := snapshotOf(30, map[string]int64{"/log/app.log.2": 40, "/log/app.log.3": 0})
+	s.CalculateGrowth(snap3, snap4, false)
+	if _, ok := tracker.samples["/log/app.log.1"]; ok || len(tracker.samples) != 1 {
+		t.Errorf("tracker holds %d files, want only /log/app.log.2", len(tracker.samples))
+	}
+}
+
+func TestBaselineTrackerConcurrentScans(t *testing.T) {
+	s := New(DefaultConfig())
+	s.SetThresholdStrategy(NewBaselineTracker(10, 3))
+
+	sizes1 := make(map[string]int64)
+	sizes2 := make(map[string]int64)
+	for i := 0; i < 100; i++ {
+		path := fmt.Sprintf("/log/%d.log", i)
+		sizes1[path], sizes2[path] = 0, int64(i)
+	}
+	snap1, snap2 := snapshotOf(0, sizes1), snapshotOf(10, sizes2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.ScanWithSnapshots(snap1, snap2)
+		}()
+	}
+	wg.Wait()
+}
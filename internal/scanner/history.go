@@ -0,0 +1,114 @@
+package scanner
+
+import (
+	"sync"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// DefaultHistorySize is the number of samples History keeps per file.
+const DefaultHistorySize = 60
+
+// History keeps a fixed number of recent size samples per file so that
+// trends can be computed across watch cycles. It is safe for concurrent use.
+type History struct {
+	mu    sync.RWMutex
+	size  int
+	files map[string]*sizeRing
+}
+
+// sizeRing is a fixed-size ring of size samples.
+type sizeRing struct {
+	samples []types.SizeSample
+	next    int
+	count   int
+}
+
+// NewHistory creates a History keeping size samples per file.
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{
+		size:  size,
+		files: make(map[string]*sizeRing),
+	}
+}
+
+// Record adds a sample for every file in the snapshot and forgets files
+// that are no longer present.
+func (h *History) Record(snap *types.Snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for path, info := range snap.Files {
+		if info.IsDir {
+			continue
+		}
+		r, ok := h.files[path]
+		if !ok {
+			r = &sizeRing{samples: make([]types.SizeSample, h.size)}
+			h.files[path] = r
+		}
+		r.add(types.SizeSample{Timestamp: snap.Timestamp, Size: info.Size})
+	}
+
+	for path := range h.files {
+		if _, ok := snap.Files[path]; !ok {
+			delete(h.files, path)
+		}
+	}
+}
+
+// Trajectory returns the recorded samples for a file, oldest first.
+func (h *History) Trajectory(path string) []types.SizeSample {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	r, ok := h.files[path]
+	if !ok {
+		return nil
+	}
+	return r.ordered()
+}
+
+// GrowingFor returns how long a file has been growing without a pause,
+// measured from the most recent sample back. It is zero if the file did
+// not grow in the latest interval.
+func (h *History) GrowingFor(path string) time.Duration {
+	samples := h.Trajectory(path)
+	if len(samples) < 2 {
+		return 0
+	}
+
+	last := len(samples) - 1
+	start := last
+	for start > 0 && samples[start].Size > samples[start-1].Size {
+		start--
+	}
+
+	return samples[last].Timestamp.Sub(samples[start].Timestamp)
+}
+
+// add records a sample, overwriting the oldest when the ring is full.
+func (r *sizeRing) add(s types.SizeSample) {
+	r.samples[r.next] = s
+	r.next = (r.next + 1) % len(r.samples)
+	if r.count < len(r.samples) {
+		r.count++
+	}
+}
+
+// ordered returns a copy of the samples, oldest first.
+func (r *sizeRing) ordered() []types.SizeSample {
+	out := make([]types.SizeSample, 0, r.count)
+	start := r.next - r.count
+	if start < 0 {
+		start += len(r.samples)
+	}
+	for i := 0; i < r.count; i++ {
+		out = append(out, r.samples[(start+i)%len(r.samples)])
+	}
+	return out
+}
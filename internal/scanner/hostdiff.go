@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"sort"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// DiffGrowth compares the growth profile of a problem host against a
+// healthy peer, e.g. ScanResult.GrowingFiles from each. Every path growing
+// on either side is returned, ordered by how much faster it grows on the
+// problem host, so the anomalous workload comes first.
+func DiffGrowth(problem, healthy []types.FileGrowth) []types.GrowthDiff {
+	diffs := make(map[string]*types.GrowthDiff)
+	entry := func(path string) *types.GrowthDiff {
+		d, ok := diffs[path]
+		if !ok {
+			d = &types.GrowthDiff{Path: path}
+			diffs[path] = d
+		}
+		return d
+	}
+
+	for _, g := range problem {
+		d := entry(g.Path)
+		d.ProblemRate = g.GrowthRate
+		d.ProblemBytes = g.GrowthBytes
+	}
+	for _, g := range healthy {
+		d := entry(g.Path)
+		d.HealthyRate = g.GrowthRate
+		d.HealthyBytes = g.GrowthBytes
+	}
+
+	result := make([]types.GrowthDiff, 0, len(diffs))
+	for _, d := range diffs {
+		result = append(result, *d)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		di := result[i].ProblemRate - result[i].HealthyRate
+		dj := result[j].ProblemRate - result[j].HealthyRate
+		if di != dj {
+			return di > dj
+		}
+		return result[i].Path < result[j].Path
+	})
+
+	return result
+}
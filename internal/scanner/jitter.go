@@ -0,0 +1,23 @@
+package scanner
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NextWait returns how long a scan loop should wait before its next cycle:
+// the interval shifted by a random amount within ±ScanJitter, so that hosts
+// started by the same timer drift apart instead of hitting shared storage
+// together. The jitter is capped at half the interval, so the wait is
+// always at least half the interval and never zero or negative.
+func (s *Scanner) NextWait() time.Duration {
+	interval := s.config.Interval
+	jitter := s.config.ScanJitter
+	if jitter > interval/2 {
+		jitter = interval / 2
+	}
+	if jitter <= 0 {
+		return interval
+	}
+	return interval - jitter + time.Duration(rand.Int63n(int64(2*jitter)+1))
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package scanner
+
+// kernelVersion is left empty where there is no uname.
+func kernelVersion() string {
+	return ""
+}
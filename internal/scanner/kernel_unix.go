@@ -0,0 +1,14 @@
+//go:build unix
+
+package scanner
+
+import "golang.org/x/sys/unix"
+
+// kernelVersion returns the kernel release as reported by uname.
+func kernelVersion() string {
+	var uts unix.Utsname
+	if err := unix.Uname(&uts); err != nil {
+		return ""
+	}
+	return unix.ByteSliceToString(uts.Release[:])
+}
@@ -0,0 +1,149 @@
+package scanner
+
+import (
+	"hash/fnv"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// compressedExts are rotation suffixes whose files re-encode output that
+// was already counted.
+var compressedExts = map[string]bool{
+	".gz": true, ".zst": true, ".bz2": true, ".xz": true, ".zip": true,
+}
+
+// LogicalTracker follows logs across rotation. Files are grouped into a
+// logical log by pattern and directory, and growth is tracked by inode, so
+// output keeps being counted when app.log is renamed to app.log.1 and a
+// fresh app.log is created.
+type LogicalTracker struct {
+	patterns []string
+	totals   map[logicalKey]*types.LogicalGrowth
+}
+
+// logicalKey identifies one logical log.
+type logicalKey struct {
+	pattern string
+	dir     string
+}
+
+// NewLogicalTracker creates a tracker for the given patterns. As with
+// exclude patterns, a bare pattern such as "app.log*" matches file names
+// in any directory, with one logical log per directory; a pattern
+// containing "/" matches the full path.
+func NewLogicalTracker(patterns ...string) *LogicalTracker {
+	return &LogicalTracker{
+		patterns: patterns,
+		totals:   make(map[logicalKey]*types.LogicalGrowth),
+	}
+}
+
+// Observe accounts for the output written to each logical log between two
+// snapshots and returns every logical log present in snap2, largest
+// cumulative output first.
+//
+// Renamed files are followed by inode. A file that shrank was truncated by
+// copytruncate rotation: its new size is fresh output and its old size
+// moved into a new copy, so new files only count beyond what moved. New
+// compressed files are not counted.
+func (t *LogicalTracker) Observe(snap1, snap2 *types.Snapshot) []types.LogicalGrowth {
+	interval := snap2.Timestamp.Sub(snap1.Timestamp)
+	if interval <= 0 {
+		interval = time.Second // Prevent division by zero
+	}
+
+	before := t.group(snap1)
+	after := t.group(snap2)
+
+	var result []types.LogicalGrowth
+	for key, files2 := range after {
+		prev := make(map[uint64]types.FileInfo)
+		for _, f := range before[key] {
+			prev[fileIdentity(f)] = f
+		}
+
+		var growth, moved, fresh int64
+		rotations := 0
+		for _, f := range files2 {
+			old, ok := prev[fileIdentity(f)]
+			switch {
+			case !ok:
+				if !compressedExts[strings.ToLower(filepath.Ext(f.Path))] {
+					fresh += f.Size
+				}
+			case f.Size < old.Size:
+				// Truncated in place after being copied
+				moved += old.Size
+				growth += f.Size
+				rotations++
+			default:
+				growth += f.Size - old.Size
+				if f.Path != old.Path {
+					rotations++
+				}
+			}
+		}
+		if fresh > moved {
+			growth += fresh - moved
+		}
+
+		total, ok := t.totals[key]
+		if !ok {
+			total = &types.LogicalGrowth{Pattern: key.pattern, Dir: key.dir}
+			t.totals[key] = total
+		}
+		total.Files = len(files2)
+		total.GrowthBytes = growth
+		total.GrowthRate = float64(growth) / interval.Seconds()
+		total.TotalBytes += growth
+		total.Rotations += rotations
+
+		result = append(result, *total)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalBytes != result[j].TotalBytes {
+			return result[i].TotalBytes > result[j].TotalBytes
+		}
+		if result[i].Dir != result[j].Dir {
+			return result[i].Dir < result[j].Dir
+		}
+		return result[i].Pattern < result[j].Pattern
+	})
+
+	return result
+}
+
+// group sorts a snapshot's files into logical logs. A file belongs to the
+// first pattern it matches.
+func (t *LogicalTracker) group(snap *types.Snapshot) map[logicalKey][]types.FileInfo {
+	groups := make(map[logicalKey][]types.FileInfo)
+	for path, info := range snap.Files {
+		if info.IsDir {
+			continue
+		}
+		for _, pattern := range t.patterns {
+			if matchExclude([]string{pattern}, path) {
+				key := logicalKey{pattern: pattern, dir: filepath.Dir(path)}
+				groups[key] = append(groups[key], info)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// fileIdentity returns the inode of a file. Without inode numbers, files are
+// identified by a hash of their path, which follows appends but not renames.
+func fileIdentity(f types.FileInfo) uint64 {
+	if f.Inode != 0 {
+		return f.Inode
+	}
+	h := fnv.New64a()
+	h.Write([]byte(f.Path))
+	return h.Sum64()
+}
@@ -0,0 +1,79 @@
+//go:build linux
+
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// statLong stats a path longer than PATH_MAX, following a final symlink.
+func statLong(name string) (os.FileInfo, error) {
+	f, err := openLong(name, unix.O_PATH)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+// readDirLong reads a directory whose path is longer than PATH_MAX, sorted
+// by name like os.ReadDir.
+func readDirLong(name string) ([]os.DirEntry, error) {
+	f, err := openLong(name, unix.O_RDONLY|unix.O_DIRECTORY)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := f.ReadDir(-1)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, err
+}
+
+// openLong opens name with openat relative to its parent directory. The
+// parent is itself opened in chunks of whole components that each fit in
+// PATH_MAX, so the full path may be any length.
+func openLong(name string, flags int) (*os.File, error) {
+	dir, base := filepath.Split(filepath.Clean(name))
+
+	dirfd := unix.AT_FDCWD
+	defer func() {
+		if dirfd != unix.AT_FDCWD {
+			unix.Close(dirfd)
+		}
+	}()
+
+	for dir != "" {
+		chunk := dir
+		if len(chunk) >= unix.PathMax {
+			i := strings.LastIndexByte(chunk[:unix.PathMax-1], '/')
+			if i < 0 {
+				return nil, &os.PathError{Op: "openat", Path: name, Err: syscall.ENAMETOOLONG}
+			}
+			chunk = chunk[:i+1]
+		}
+		fd, err := unix.Openat(dirfd, chunk, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return nil, &os.PathError{Op: "openat", Path: name, Err: err}
+		}
+		if dirfd != unix.AT_FDCWD {
+			unix.Close(dirfd)
+		}
+		dirfd = fd
+		dir = dir[len(chunk):]
+	}
+
+	fd, err := unix.Openat(dirfd, base, flags|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "openat", Path: name, Err: err}
+	}
+	return os.NewFile(uintptr(fd), name), nil
+}
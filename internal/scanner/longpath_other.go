@@ -0,0 +1,18 @@
+//go:build !linux
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// statLong fails: paths longer than PATH_MAX are only handled on Linux.
+func statLong(name string) (os.FileInfo, error) {
+	return nil, &os.PathError{Op: "stat", Path: name, Err: syscall.ENAMETOOLONG}
+}
+
+// readDirLong fails: paths longer than PATH_MAX are only handled on Linux.
+func readDirLong(name string) ([]os.DirEntry, error) {
+	return nil, &os.PathError{Op: "readdirent", Path: name, Err: syscall.ENAMETOOLONG}
+}
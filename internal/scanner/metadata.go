@@ -0,0 +1,31 @@
+package scanner
+
+import (
+	"os"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Version is recorded in snapshot metadata. The CLI sets it from its build
+// version at startup.
+var Version = "dev"
+
+// metadata describes the host and scanner configuration for a snapshot.
+func (s *Scanner) metadata() *types.SnapshotMetadata {
+	hostname, _ := os.Hostname()
+	threshold, _ := s.Thresholds()
+
+	return &types.SnapshotMetadata{
+		Hostname:           hostname,
+		KernelVersion:      kernelVersion(),
+		Version:            Version,
+		Paths:              append([]string(nil), s.config.Paths...),
+		ExcludePatterns:    append([]string(nil), s.config.ExcludePatterns...),
+		ExcludeDirs:        append([]string(nil), s.config.ExcludeDirs...),
+		ThresholdBytes:     threshold,
+		MaxDepth:           s.config.MaxDepth,
+		FollowSymlinks:     s.config.FollowSymlinks,
+		FollowDirSymlinks:  s.config.followDirLinks(),
+		FollowFileSymlinks: s.config.followFileLinks(),
+	}
+}
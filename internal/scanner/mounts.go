@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// mountUsage returns filesystem usage for the mounts containing paths.
+// Statfs reports each mount's own capacity, so tmpfs mounts get their size
+// limit rather than the disk underneath. Usage is only known for the host
+// filesystem, so it is nil when the scan reads another FS.
+func (c Config) mountUsage(paths []string) map[string]types.MountUsage {
+	if c.fs() != OSFS {
+		return nil
+	}
+
+	usage := make(map[string]types.MountUsage)
+	table := readMountTable()
+
+	for _, path := range paths {
+		mount, err := mountPoint(path)
+		if err != nil {
+			continue
+		}
+		if _, seen := usage[mount]; seen {
+			continue
+		}
+
+		total, free, err := diskUsage(mount)
+		if err != nil {
+			continue
+		}
+		usage[mount] = types.MountUsage{
+			TotalBytes:     total,
+			FreeBytes:      free,
+			FilesystemType: table.typeOf(mount),
+		}
+	}
+
+	return usage
+}
+
+// mountPoint walks up from path on the host filesystem until the device
+// changes.
+func mountPoint(path string) (string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	dev, err := deviceOf(OSFS, path)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path, nil
+		}
+		parentDev, err := deviceOf(OSFS, parent)
+		if err != nil || parentDev != dev {
+			return path, nil
+		}
+		path = parent
+	}
+}
+
+// deviceOf returns the device ID of the filesystem holding path.
+func deviceOf(fsys FS, path string) (uint64, error) {
+	info, err := fsys.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	dev, _, _, ok := statIDs(info)
+	if !ok {
+		return 0, os.ErrInvalid
+	}
+	return dev, nil
+}
+
+// PredictDiskFull fits a linear trend to each mount's free space across
+// history (oldest first) and returns when it is projected to reach zero.
+// Mounts whose free space is stable or growing map to the zero time.
+func (s *Scanner) PredictDiskFull(history []*types.Snapshot) map[string]time.Time {
+	type point struct {
+		t    time.Time
+		free float64
+	}
+	series := make(map[string][]point)
+
+	for _, snap := range history {
+		if snap == nil {
+			continue
+		}
+		for mount, usage := range snap.Mounts {
+			series[mount] = append(series[mount], point{t: snap.Timestamp, free: float64(usage.FreeBytes)})
+		}
+	}
+
+	predictions := make(map[string]time.Time)
+	for mount, points := range series {
+		if len(points) < 2 {
+			predictions[mount] = time.Time{}
+			continue
+		}
+
+		// Least-squares fit of free bytes against seconds since the first point
+		origin := points[0].t
+		var sumX, sumY, sumXY, sumXX float64
+		for _, p := range points {
+			x := p.t.Sub(origin).Seconds()
+			sumX += x
+			sumY += p.free
+			sumXY += x * p.free
+			sumXX += x * x
+		}
+		n := float64(len(points))
+		denom := n*sumXX - sumX*sumX
+		if denom == 0 {
+			predictions[mount] = time.Time{}
+			continue
+		}
+		slope := (n*sumXY - sumX*sumY) / denom
+		if slope >= 0 {
+			predictions[mount] = time.Time{}
+			continue
+		}
+		intercept := (sumY - slope*sumX) / n
+
+		secondsToFull := -intercept / slope
+		predictions[mount] = origin.Add(time.Duration(secondsToFull * float64(time.Second)))
+	}
+
+	return predictions
+}
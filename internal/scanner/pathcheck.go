@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// ErrPathMismatch is returned when two snapshots were taken over different
+// scan paths, so files covered by only one of them would show up as new or
+// removed.
+var ErrPathMismatch = errors.New("snapshots cover different scan paths")
+
+// CheckSnapshotPaths reports whether two snapshots were taken over the same
+// scan paths, ignoring order. Snapshots without metadata can't be checked
+// and are assumed to match.
+func CheckSnapshotPaths(snap1, snap2 *types.Snapshot) error {
+	if snap1.Metadata == nil || snap2.Metadata == nil {
+		return nil
+	}
+
+	paths1 := pathSet(snap1.Metadata.Paths)
+	paths2 := pathSet(snap2.Metadata.Paths)
+
+	var onlyFirst, onlySecond []string
+	for p := range paths1 {
+		if !paths2[p] {
+			onlyFirst = append(onlyFirst, p)
+		}
+	}
+	for p := range paths2 {
+		if !paths1[p] {
+			onlySecond = append(onlySecond, p)
+		}
+	}
+	if len(onlyFirst) == 0 && len(onlySecond) == 0 {
+		return nil
+	}
+
+	sort.Strings(onlyFirst)
+	sort.Strings(onlySecond)
+	return fmt.Errorf("%w: only in first [%s], only in second [%s]", ErrPathMismatch,
+		strings.Join(onlyFirst, ", "), strings.Join(onlySecond, ", "))
+}
+
+// RestrictToCommonPaths returns copies of two snapshots holding only the
+// files covered by both snapshots' scan paths, so they can be compared even
+// when the paths differ. Totals are recomputed. Snapshots without metadata
+// are returned unchanged.
+func RestrictToCommonPaths(snap1, snap2 *types.Snapshot) (*types.Snapshot, *types.Snapshot) {
+	if snap1.Metadata == nil || snap2.Metadata == nil {
+		return snap1, snap2
+	}
+
+	bases1 := cleanPaths(snap1.Metadata.Paths)
+	bases2 := cleanPaths(snap2.Metadata.Paths)
+	covered := func(path string) bool {
+		return coveredBy(path, bases1) && coveredBy(path, bases2)
+	}
+
+	return restrictSnapshot(snap1, covered), restrictSnapshot(snap2, covered)
+}
+
+// restrictSnapshot copies snap keeping only files for which keep is true.
+func restrictSnapshot(snap *types.Snapshot, keep func(path string) bool) *types.Snapshot {
+	restricted := *snap
+	restricted.Files = make(map[string]types.FileInfo)
+	restricted.TotalSize = 0
+	restricted.FileCount = 0
+	restricted.Dirs = nil
+
+	for path, info := range snap.Files {
+		if keep(path) {
+			addToSnapshot(&restricted, info)
+		}
+	}
+	if snap.Dirs != nil {
+		restricted.Dirs = make(map[string]int64)
+		for dir, size := range snap.Dirs {
+			if keep(dir) {
+				restricted.Dirs[dir] = size
+			}
+		}
+	}
+
+	return &restricted
+}
+
+// coveredBy reports whether path lies under one of bases, which may be glob
+// patterns matching a directory above path.
+func coveredBy(path string, bases []string) bool {
+	for _, base := range bases {
+		if !hasGlob(base) {
+			if pathHasPrefix(path, base) {
+				return true
+			}
+			continue
+		}
+		for dir := path; ; dir = filepath.Dir(dir) {
+			if ok, _ := filepath.Match(base, dir); ok {
+				return true
+			}
+			if dir == filepath.Dir(dir) {
+				break
+			}
+		}
+	}
+	return false
+}
+
+// pathSet returns the cleaned paths as a set.
+func pathSet(paths []string) map[string]bool {
+	set := make(map[string]bool, len(paths))
+	for _, p := range cleanPaths(paths) {
+		set[p] = true
+	}
+	return set
+}
+
+// cleanPaths returns paths with filepath.Clean applied.
+func cleanPaths(paths []string) []string {
+	cleaned := make([]string, len(paths))
+	for i, p := range paths {
+		cleaned[i] = filepath.Clean(p)
+	}
+	return cleaned
+}
@@ -0,0 +1,73 @@
+package scanner
+
+import (
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// ClassifyGrowth labels each file seen in at least two snapshots of history
+// (oldest first) by how its size changed: appended to, rewritten, rotated
+// or stable.
+func (s *Scanner) ClassifyGrowth(history []*types.Snapshot) map[string]types.GrowthPattern {
+	series := make(map[string][]types.FileInfo)
+	for _, snap := range history {
+		if snap == nil {
+			continue
+		}
+		for path, info := range snap.Files {
+			if info.IsDir {
+				continue
+			}
+			series[path] = append(series[path], info)
+		}
+	}
+
+	patterns := make(map[string]types.GrowthPattern)
+	for path, infos := range series {
+		if len(infos) < 2 {
+			continue
+		}
+		patterns[path] = classifySeries(infos)
+	}
+
+	return patterns
+}
+
+// classifySeries classifies one file's observations in time order.
+func classifySeries(infos []types.FileInfo) types.GrowthPattern {
+	var increases, decreases int
+	var truncated, modified bool
+
+	for i := 1; i < len(infos); i++ {
+		prev, cur := infos[i-1], infos[i]
+
+		if prev.Inode != 0 && cur.Inode != 0 && prev.Inode != cur.Inode {
+			return types.GrowthRotated
+		}
+
+		switch {
+		case cur.Size > prev.Size:
+			increases++
+		case cur.Size < prev.Size:
+			decreases++
+			// A drop to less than half looks like copytruncate rotation
+			if cur.Size < prev.Size/2 {
+				truncated = true
+			}
+		case !cur.ModTime.Equal(prev.ModTime):
+			modified = true
+		}
+	}
+
+	switch {
+	case decreases == 1 && truncated:
+		return types.GrowthRotated
+	case decreases > 0:
+		return types.GrowthRewrite
+	case modified && increases == 0:
+		return types.GrowthRewrite
+	case increases > 0:
+		return types.GrowthAppend
+	default:
+		return types.GrowthStable
+	}
+}
@@ -0,0 +1,122 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// walkEntry is a file found by the walk, with the type ReadDir reported so
+// the worker that stats it can tell if it changed in between.
+type walkEntry struct {
+	path string
+	typ  os.FileMode
+}
+
+// typeChanged reports whether a file's type at stat time differs from the
+// type the walk saw. Symlinks reaching the workers were already resolved to
+// non-directories by filterFile, so only a directory is unexpected there.
+func typeChanged(walked, now os.FileMode) bool {
+	if walked&os.ModeSymlink != 0 {
+		return now.IsDir()
+	}
+	return walked.Type() != now.Type()
+}
+
+// vanishedDirs collects the directories of files that disappeared between
+// ReadDir and stat, usually because they were renamed or rotated.
+type vanishedDirs struct {
+	mu   sync.Mutex
+	dirs map[string]bool
+}
+
+// add records the directory of a vanished file.
+func (v *vanishedDirs) add(path string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.dirs == nil {
+		v.dirs = make(map[string]bool)
+	}
+	v.dirs[filepath.Dir(path)] = true
+}
+
+// rescan re-reads each directory that lost a file during the walk and
+// passes the files not yet seen to add, so a file renamed within its
+// directory is still counted under its new name.
+func (s *Scanner) rescan(ctx context.Context, c Config, v *vanishedDirs, seen func(string) bool, add func(types.FileInfo)) {
+	dirs := make([]string, 0, len(v.dirs))
+	for dir := range v.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if ctx.Err() != nil {
+			return
+		}
+		entries, err := c.fs().ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		recovered := 0
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if seen(path) {
+				continue
+			}
+			visit, isDir := c.filterFile(path, entry)
+			if !visit || isDir {
+				continue
+			}
+			info, mode, err := s.statFileMode(path)
+			if err != nil || mode.IsDir() {
+				continue
+			}
+			if seen(info.Path) {
+				continue
+			}
+			add(c.label(info))
+			recovered++
+		}
+		c.logger().Debug("rescanned directory after a file vanished during the walk",
+			slog.String("dir", dir), slog.Int("recovered", recovered))
+	}
+}
+
+// statWalked stats a file found by the walk. A file that vanished since
+// ReadDir has its directory recorded in v for rescanning, and one that
+// changed type is skipped; both races are logged at debug level.
+func (s *Scanner) statWalked(c Config, e walkEntry, v *vanishedDirs) (types.FileInfo, bool) {
+	info, mode, err := s.statFileMode(e.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			c.logger().Debug("file vanished between readdir and stat", slog.String("path", e.path))
+			v.add(e.path)
+		}
+		c.noteSkipped(err)
+		return types.FileInfo{}, false
+	}
+	if typeChanged(e.typ, mode) {
+		c.logger().Debug("file changed type between readdir and stat",
+			slog.String("path", e.path),
+			slog.String("walked", e.typ.Type().String()),
+			slog.String("now", mode.Type().String()))
+		return types.FileInfo{}, false
+	}
+	return c.label(info), true
+}
+
+// logger returns the logger for scan diagnostics.
+func (c Config) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// renameFS renames from to to in fsys the first time from is stat'd, and
+// reports from as gone, like a log rotated between ReadDir and stat.
+type renameFS struct {
+	mu       sync.Mutex
+	fsys     fstest.MapFS
+	from, to string
+}
+
+func (r *renameFS) ReadDir(name string) ([]os.DirEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return FromIOFS(r.fsys).ReadDir(name)
+}
+
+func (r *renameFS) Stat(name string) (os.FileInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if name == "/"+r.from {
+		if f, ok := r.fsys[r.from]; ok {
+			delete(r.fsys, r.from)
+			r.fsys[r.to] = f
+			return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	return FromIOFS(r.fsys).Stat(name)
+}
+
+func TestTakeSnapshotRescanUnderCap(t *testing.T) {
+	config := DefaultConfig()
+	config.Paths = []string{"/srv/log"}
+	config.FS = &renameFS{
+		fsys: fstest.MapFS{
+			"srv/log/app.log":   {Data: make([]byte, 100)},
+			"srv/log/a.log":     {Data: make([]byte, 10)},
+			"srv/log/b.log":     {Data: make([]byte, 20)},
+			"srv/log/sub/c.log": {Data: make([]byte, 5)},
+		},
+		from: "srv/log/app.log",
+		to:   "srv/log/app.log.1",
+	}
+	config.MaxFiles = 2
+	config.TrackDirs = true
+
+	snapshot, err := New(config).TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]int64)
+	for path, info := range snapshot.Files {
+		got[path] = info.Size
+	}
+	want := map[string]int64{"/srv/log/app.log.1": 100, "/srv/log/b.log": 20}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshot files = %v, want %v", got, want)
+	}
+	if !snapshot.Truncated {
+		t.Error("snapshot not marked truncated")
+	}
+	if size := snapshot.Dirs["/srv/log"]; size != 135 {
+		t.Errorf("Dirs[/srv/log] = %d, want 135", size)
+	}
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// ReplaySnapshots loads every snapshot in dir in timestamp order and returns
+// a ScanResult for each consecutive pair. Every file that grew is reported.
+// Files that cannot be parsed, and snapshots whose timestamp duplicates an
+// earlier one, are skipped with a warning to the configured logger.
+func (s *Scanner) ReplaySnapshots(dir string) ([]*types.ScanResult, error) {
+	store := NewSnapshotStore(dir)
+
+	names, err := store.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var snapshots []*types.Snapshot
+	for _, name := range names {
+		snap, err := store.Load(name)
+		if err != nil {
+			s.config.logger().Warn("replay: skipping unreadable snapshot",
+				slog.String("name", name), slog.Any("error", err))
+			continue
+		}
+		if snap.Timestamp.IsZero() {
+			s.config.logger().Warn("replay: skipping snapshot without a timestamp",
+				slog.String("name", name))
+			continue
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Timestamp.Before(snapshots[j].Timestamp)
+	})
+
+	var results []*types.ScanResult
+	var prev *types.Snapshot
+	for _, snap := range snapshots {
+		if prev != nil && !snap.Timestamp.After(prev.Timestamp) {
+			s.config.logger().Warn("replay: skipping snapshot not after the previous one",
+				slog.Time("timestamp", snap.Timestamp))
+			continue
+		}
+		if prev != nil {
+			results = append(results, replayPair(prev, snap))
+		}
+		prev = snap
+	}
+
+	return results, nil
+}
+
+// replayPair builds a ScanResult for two consecutive snapshots.
+func replayPair(snap1, snap2 *types.Snapshot) *types.ScanResult {
+	growing := CompareSnapshots(snap1, snap2, 1)
+	sort.Slice(growing, func(i, j int) bool {
+		return growing[i].GrowthRate > growing[j].GrowthRate
+	})
+
+	result := &types.ScanResult{
+		StartTime:    snap1.Timestamp,
+		EndTime:      snap2.Timestamp,
+		Interval:     snap2.Timestamp.Sub(snap1.Timestamp),
+		Snapshot1:    snap1,
+		Snapshot2:    snap2,
+		GrowingFiles: growing,
+	}
+	if snap2.Metadata != nil {
+		result.Paths = snap2.Metadata.Paths
+	}
+	for _, g := range growing {
+		result.TotalGrowth += g.GrowthBytes
+	}
+
+	return result
+}
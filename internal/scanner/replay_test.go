@@ -0,0 +1,54 @@
+package scanner
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+func TestReplaySnapshotsSkipsWithWarnings(t *testing.T) {
+	dir := t.TempDir()
+	store := NewSnapshotStore(dir)
+	for name, snap := range map[string]*types.Snapshot{
+		"1.json":       snapshotOf(0, map[string]int64{"/log/a": 10}),
+		"2.json":       snapshotOf(10, map[string]int64{"/log/a": 40}),
+		"2b.json":      snapshotOf(10, map[string]int64{"/log/a": 99}),
+		"3.json":       snapshotOf(20, map[string]int64{"/log/a": 50}),
+		"no-time.json": {},
+	} {
+		if err := store.Save(snap, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var logs bytes.Buffer
+	config := DefaultConfig()
+	config.Logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+	results, err := New(config).ReplaySnapshots(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var growth []int64
+	for _, r := range results {
+		growth = append(growth, r.TotalGrowth)
+	}
+	if len(growth) != 2 || growth[0] != 30 || growth[1] != 10 {
+		t.Errorf("replayed growth = %v, want [30 10]", growth)
+	}
+
+	for _, want := range []string{"name=broken.json", "name=no-time.json", "not after the previous one"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q:\n%s", want, logs.String())
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package scanner
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// checkpoint is the on-disk state of an interrupted snapshot.
+type checkpoint struct {
+	Paths    []string
+	Snapshot *types.Snapshot
+	DoneDirs []string
+}
+
+// resumeState tracks progress of a resumable snapshot.
+type resumeState struct {
+	snapshot *types.Snapshot
+	done     map[string]bool
+	path     string
+	every    time.Duration
+	lastSave time.Time
+}
+
+// ResumableSnapshot takes a snapshot like TakeSnapshot but checkpoints its
+// progress to checkpointPath every interval. If a checkpoint for the same
+// paths exists, files in directories it already covered are not re-stat'd.
+// When ctx is cancelled, progress is checkpointed before ctx.Err() is
+// returned. The checkpoint is removed once the snapshot completes.
+//
+// Files are stat'd one at a time and all of them are held until the walk
+// completes, so the checkpoint can skip finished directories; MaxFiles is
+// applied to the finished snapshot. A resumed snapshot is stamped with the
+// time it was resumed, though files from directories covered before the
+// interruption were stat'd earlier.
+func (s *Scanner) ResumableSnapshot(ctx context.Context, checkpointPath string, every time.Duration) (*types.Snapshot, error) {
+	state := &resumeState{
+		done:     make(map[string]bool),
+		path:     checkpointPath,
+		every:    every,
+		lastSave: time.Now(),
+	}
+
+	if cp, err := loadCheckpoint(checkpointPath); err == nil && samePaths(cp.Paths, s.config.Paths) {
+		state.snapshot = cp.Snapshot
+		for _, dir := range cp.DoneDirs {
+			state.done[dir] = true
+		}
+	}
+	if state.snapshot == nil || state.snapshot.Files == nil {
+		state.snapshot = &types.Snapshot{Files: make(map[string]types.FileInfo)}
+	}
+	snapshot := state.snapshot
+	snapshot.Timestamp = time.Now()
+	snapshot.Metadata = s.metadata()
+
+	var longPaths atomic.Int64
+	config := s.config
+	config.longPaths = &longPaths
+	config.self = newSelfFilter(s.config.SelfPaths).withOpenFiles()
+	config.mounts = readMountTable()
+
+	var vanished vanishedDirs
+	hooks := walkHooks{
+		skipFiles: func(dir string) bool { return state.done[dir] },
+		leave: func(dir string) error {
+			state.done[dir] = true
+			if state.every > 0 && time.Since(state.lastSave) >= state.every {
+				return s.saveCheckpoint(state)
+			}
+			return nil
+		},
+	}
+	add := func(info types.FileInfo) {
+		snapshot.Files[info.Path] = info
+	}
+	seen := func(path string) bool {
+		_, ok := snapshot.Files[path]
+		return ok
+	}
+
+	for _, basePath := range s.basePaths() {
+		err := walkTreeHooks(ctx, config, basePath, 0, hooks, func(path string, typ os.FileMode) error {
+			if info, ok := s.statWalked(config, walkEntry{path: path, typ: typ}, &vanished); ok {
+				add(info)
+			}
+			return nil
+		})
+		if err != nil {
+			if saveErr := s.saveCheckpoint(state); saveErr != nil {
+				return nil, saveErr
+			}
+			return nil, err
+		}
+	}
+	if len(vanished.dirs) > 0 {
+		s.rescan(ctx, config, &vanished, seen, add)
+	}
+	if err := ctx.Err(); err != nil {
+		if saveErr := s.saveCheckpoint(state); saveErr != nil {
+			return nil, saveErr
+		}
+		return nil, err
+	}
+
+	if s.config.TrackDirs {
+		s.recordDirSizes(snapshot)
+	}
+	if s.config.MaxFiles > 0 && len(snapshot.Files) > s.config.MaxFiles {
+		largest := &largestFiles{n: s.config.MaxFiles}
+		for _, info := range snapshot.Files {
+			largest.add(info)
+		}
+		snapshot.Files = make(map[string]types.FileInfo, len(largest.h))
+		for _, info := range largest.h {
+			snapshot.Files[info.Path] = info
+		}
+		snapshot.Truncated = true
+	}
+
+	snapshot.TotalSize = 0
+	snapshot.FileCount = 0
+	for _, info := range snapshot.Files {
+		if !info.IsDir {
+			snapshot.TotalSize += info.Size
+			snapshot.FileCount++
+		}
+	}
+	snapshot.Mounts = s.config.mountUsage(s.basePaths())
+	snapshot.LongPathsSkipped = int(longPaths.Load())
+
+	if err := os.Remove(checkpointPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// saveCheckpoint atomically writes the current progress to disk.
+func (s *Scanner) saveCheckpoint(state *resumeState) error {
+	cp := checkpoint{
+		Paths:    s.config.Paths,
+		Snapshot: state.snapshot,
+	}
+	for dir := range state.done {
+		cp.DoneDirs = append(cp.DoneDirs, dir)
+	}
+	sort.Strings(cp.DoneDirs)
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmp := state.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, state.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	state.lastSave = time.Now()
+	return nil
+}
+
+// loadCheckpoint reads a checkpoint from disk.
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+
+	return &cp, nil
+}
+
+// samePaths reports whether two path lists are identical.
+func samePaths(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
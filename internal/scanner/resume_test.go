@@ -0,0 +1,117 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// countingFS counts Stat calls on files and cancels a context once it has
+// seen cancelAfter of them, if set.
+type countingFS struct {
+	FS
+	mu          sync.Mutex
+	stats       int
+	cancelAfter int
+	cancel      context.CancelFunc
+}
+
+func (f *countingFS) Stat(name string) (os.FileInfo, error) {
+	if strings.HasSuffix(name, ".log") {
+		f.mu.Lock()
+		f.stats++
+		if f.cancel != nil && f.stats == f.cancelAfter {
+			f.cancel()
+		}
+		f.mu.Unlock()
+	}
+	return f.FS.Stat(name)
+}
+
+func TestResumableSnapshotResumes(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for d := 0; d < 5; d++ {
+		for f := 0; f < 4; f++ {
+			fsys[fmt.Sprintf("srv/log/d%d/f%d.log", d, f)] = &fstest.MapFile{Data: make([]byte, d*10+f)}
+		}
+	}
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	newScanner := func(fs FS) *Scanner {
+		config := DefaultConfig()
+		config.Paths = []string{"/srv/log"}
+		config.FS = fs
+		return New(config)
+	}
+
+	// Interrupt the first attempt part way through
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	first := &countingFS{FS: FromIOFS(fsys), cancelAfter: 10, cancel: cancel}
+	if _, err := newScanner(first).ResumableSnapshot(ctx, checkpoint, 0); err != context.Canceled {
+		t.Fatalf("interrupted ResumableSnapshot error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := os.Stat(checkpoint); err != nil {
+		t.Fatalf("no checkpoint after cancellation: %v", err)
+	}
+
+	resumed := time.Now()
+	second := &countingFS{FS: FromIOFS(fsys)}
+	snapshot, err := newScanner(second).ResumableSnapshot(context.Background(), checkpoint, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.stats > 20-first.stats+4 {
+		t.Errorf("resume stat'd %d files after %d were done, want finished directories skipped", second.stats, first.stats)
+	}
+	if snapshot.Timestamp.Before(resumed) {
+		t.Errorf("snapshot stamped %v, before it was resumed at %v", snapshot.Timestamp, resumed)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after completion: %v", err)
+	}
+
+	full, err := newScanner(FromIOFS(fsys)).TakeSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snapshot.Files, full.Files) {
+		t.Errorf("resumed snapshot has %d files, want the same %d as TakeSnapshot", len(snapshot.Files), len(full.Files))
+	}
+	if snapshot.FileCount != 20 || snapshot.TotalSize != full.TotalSize {
+		t.Errorf("resumed totals = %d files, %d bytes; want 20 files, %d bytes",
+			snapshot.FileCount, snapshot.TotalSize, full.TotalSize)
+	}
+}
+
+func TestResumableSnapshotMaxFiles(t *testing.T) {
+	config := DefaultConfig()
+	config.Paths = []string{"/srv/log"}
+	config.FS = FromIOFS(fstest.MapFS{
+		"srv/log/a.log":     {Data: make([]byte, 30)},
+		"srv/log/b.log":     {Data: make([]byte, 20)},
+		"srv/log/sub/c.log": {Data: make([]byte, 10)},
+	})
+	config.MaxFiles = 2
+	config.TrackDirs = true
+
+	snapshot, err := New(config).ResumableSnapshot(context.Background(), filepath.Join(t.TempDir(), "cp.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Truncated || len(snapshot.Files) != 2 || snapshot.TotalSize != 50 {
+		t.Errorf("snapshot holds %d files of %d bytes, truncated %v; want the 2 largest, 50 bytes, truncated",
+			len(snapshot.Files), snapshot.TotalSize, snapshot.Truncated)
+	}
+	if snapshot.Dirs["/srv/log"] != 60 {
+		t.Errorf("Dirs[/srv/log] = %d, want 60 counting the dropped file", snapshot.Dirs["/srv/log"])
+	}
+}
@@ -3,24 +3,68 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
+	"github.com/thiruk/logmonster/internal/audit"
 	"github.com/thiruk/logmonster/pkg/types"
+	"github.com/thiruk/logmonster/pkg/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Config holds scanner configuration.
 type Config struct {
-	Paths           []string
-	Interval        time.Duration
-	ThresholdBytes  int64
-	WorkerCount     int
-	MaxDepth        int
-	FollowSymlinks  bool
-	ExcludePatterns []string
+	Paths              []string // may contain glob patterns, re-expanded each scan
+	Interval           time.Duration
+	ScanJitter         time.Duration // random ± offset applied by NextWait
+	ThresholdBytes     int64
+	RateThreshold      float64 // bytes per second; 0 = no rate threshold
+	WorkerCount        int
+	MaxDepth           int
+	FollowSymlinks     bool    // shortcut for both of the below
+	FollowDirSymlinks  bool    // descend into symlinked directories
+	FollowFileSymlinks bool    // scan symlinked files
+	ResolveSymlinks    bool    // key files by their real path so symlinks and targets collapse
+	ReportRemoved      bool    // list files present in the first snapshot but not the second
+	MaxFiles           int     // keep only the largest files beyond this many; 0 = unlimited
+	TopN               int     // report only the N fastest-growing files; 0 = all
+	ChurnRate          float64 // files created per second that marks a churn hotspot; 0 = default
+	ExcludePatterns    []string
+	ExcludeDirs        []string     // absolute directory prefixes whose subtrees are skipped
+	SelfPaths          []string     // directories logmonster writes to, e.g. the snapshot store
+	StayOnDevice       bool         // don't descend into other filesystems, like find -xdev
+	PendingNewFiles    bool         // re-judge new files below threshold on the next call, see CalculateGrowth
+	TrackDirs          bool         // record aggregate directory sizes and report growing directories
+	FS                 FS           // filesystem to scan; nil = the host filesystem
+	Logger             *slog.Logger // debug diagnostics such as files changing mid-walk; nil = slog.Default()
+
+	// rootDevice is the device of the path being walked, set by rooted.
+	rootDevice uint64
+
+	// linkedDirs holds the directories entered through symlinks during a
+	// walk, set by rooted.
+	linkedDirs map[dirKey]bool
+
+	// longPaths counts entries skipped because their path was too long for
+	// the OS, when set by TakeSnapshot.
+	longPaths *atomic.Int64
+
+	// self recognizes logmonster's own files, when set for a snapshot.
+	self *selfFilter
+
+	// mounts maps mount points to filesystem types, when set for a snapshot.
+	mounts mountTable
 }
 
 // DefaultConfig returns a default scanner configuration.
@@ -29,95 +73,314 @@ func DefaultConfig() Config {
 		Paths:          []string{"/var/log", "/tmp"},
 		Interval:       5 * time.Second,
 		ThresholdBytes: 10 * 1024 * 1024, // 10 MB
-		WorkerCount:    4,
+		WorkerCount:    0,                // auto
 		MaxDepth:       10,
 		FollowSymlinks: false,
 	}
 }
 
+// Bounds for the automatically chosen worker count.
+const (
+	minAutoWorkers = 2
+	maxAutoWorkers = 32
+)
+
 // Scanner handles file scanning and growth detection.
 type Scanner struct {
 	config Config
+
+	// mu guards the thresholds, which start from the config but can be
+	// changed while scans run.
+	mu            sync.RWMutex
+	threshold     int64
+	rateThreshold float64
+
+	// globs caches the expansion of glob patterns in config.Paths.
+	globs globCache
+
+	// tracer is set by WithTracer; nil disables tracing.
+	tracer trace.Tracer
+
+	// allowlist is set by SetAllowlist; nil disables stable-file learning.
+	allowlist *Allowlist
+
+	// strategy is set by SetThresholdStrategy; nil applies the thresholds.
+	strategy ThresholdStrategy
+
+	// pending holds new files below the thresholds, with the time they were
+	// last known absent, carried between CalculateGrowth calls when
+	// PendingNewFiles is set.
+	pendingMu sync.Mutex
+	pending   map[string]time.Time
+
+	// auditLog is set by SetAuditLog; nil disables auditing.
+	auditLog *audit.Log
+
+	// lastCount is the number of entries in the previous snapshot, used to
+	// size the next one since trees change little between scans.
+	lastCount atomic.Int64
 }
 
 // New creates a new Scanner with the given configuration.
+// A WorkerCount of zero or less selects a worker count based on the CPU count.
 func New(config Config) *Scanner {
 	if config.WorkerCount <= 0 {
-		config.WorkerCount = 4
+		config.WorkerCount = autoWorkerCount()
+	}
+	return &Scanner{
+		config:        config,
+		threshold:     config.ThresholdBytes,
+		rateThreshold: config.RateThreshold,
+	}
+}
+
+// autoWorkerCount returns runtime.NumCPU clamped to a sane range.
+func autoWorkerCount() int {
+	n := runtime.NumCPU()
+	if n < minAutoWorkers {
+		return minAutoWorkers
+	}
+	if n > maxAutoWorkers {
+		return maxAutoWorkers
+	}
+	return n
+}
+
+// SetThreshold sets the minimum growth in bytes for a file to be reported.
+// It takes effect from the next growth calculation.
+func (s *Scanner) SetThreshold(bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.threshold = bytes
+}
+
+// SetRateThreshold sets the minimum growth rate in bytes per second for a
+// file to be reported; zero disables the rate check. It takes effect from
+// the next growth calculation.
+func (s *Scanner) SetRateThreshold(bps float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rateThreshold = bps
+}
+
+// SetAllowlist makes CalculateGrowth learn which files never grow enough to
+// be flagged and skip them, see Allowlist. Pass nil to stop. Set it before
+// scans start.
+func (s *Scanner) SetAllowlist(a *Allowlist) {
+	s.allowlist = a
+}
+
+// SetThresholdStrategy replaces the byte and rate thresholds CalculateGrowth
+// flags files with. Pass nil to go back to them. Set it before scans start.
+func (s *Scanner) SetThresholdStrategy(st ThresholdStrategy) {
+	s.strategy = st
+}
+
+// calculator returns a GrowthCalculator for the strategy set with
+// SetThresholdStrategy, or for the current thresholds.
+func (s *Scanner) calculator() GrowthCalculator {
+	if s.strategy != nil {
+		return GrowthCalculator{Strategy: s.strategy}
+	}
+	minBytes, minRate := s.Thresholds()
+	return GrowthCalculator{Strategy: RateThreshold{Bytes: minBytes, BytesPerSec: minRate}}
+}
+
+// SetAuditLog records each scan start and flagged file to l. Set it before
+// scans start.
+func (s *Scanner) SetAuditLog(l *audit.Log) {
+	s.auditLog = l
+}
+
+// auditFlagged records each flagged file to the audit log. Write failures
+// are left for audit.Log.Err to report rather than failing the scan.
+func (s *Scanner) auditFlagged(growing []types.FileGrowth) {
+	for _, g := range growing {
+		_ = s.auditLog.Record(audit.Event{
+			Type:        audit.FileFlagged,
+			Path:        g.Path,
+			GrowthBytes: g.GrowthBytes,
+			GrowthRate:  g.GrowthRate,
+			Detail:      g.Reason,
+		})
+	}
+}
+
+// Thresholds returns the current byte and rate thresholds.
+func (s *Scanner) Thresholds() (bytes int64, bps float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.threshold, s.rateThreshold
+}
+
+// exceeds reports whether a growth amount and rate cross both thresholds.
+func exceeds(growth int64, rate float64, minBytes int64, minRate float64) bool {
+	return growth >= minBytes && rate >= minRate
+}
+
+// normalizedRate converts a bytes-per-second rate to bytes per configured
+// interval, so cycles stay comparable when the real interval wobbles. With no
+// interval configured it falls back to the actual one.
+func (s *Scanner) normalizedRate(rate float64, actual time.Duration) float64 {
+	interval := s.config.Interval
+	if interval <= 0 {
+		interval = actual
+	}
+	return rate * interval.Seconds()
+}
+
+// growthReason explains why a file crossed the thresholds.
+func growthReason(growth int64, rate, minRate float64, isNew bool) string {
+	switch {
+	case isNew:
+		return "new file over threshold (" + util.FormatBytes(growth) + ")"
+	case minRate > 0:
+		return fmt.Sprintf("rate %s exceeds %s", util.FormatRate(rate), util.FormatRate(minRate))
+	default:
+		return "grew " + util.FormatBytesWithSign(growth)
 	}
-	return &Scanner{config: config}
 }
 
 // Scan performs a full scan operation: takes two snapshots and calculates growth.
 func (s *Scanner) Scan(ctx context.Context) (*types.ScanResult, error) {
-	result := &types.ScanResult{
-		StartTime: time.Now(),
-		Paths:     s.config.Paths,
-		Interval:  s.config.Interval,
-	}
+	return s.scan(ctx, false)
+}
+
+// WarmupScan is Scan for the first cycle of a monitoring loop. Files created
+// between its two snapshots have no baseline yet, so they are not reported;
+// see CalculateGrowth.
+func (s *Scanner) WarmupScan(ctx context.Context) (*types.ScanResult, error) {
+	return s.scan(ctx, true)
+}
+
+// scan takes two snapshots the interval apart and compares them.
+func (s *Scanner) scan(ctx context.Context, warmup bool) (result *types.ScanResult, err error) {
+	ctx, span := s.startSpan(ctx, "Scan",
+		attribute.StringSlice("logmonster.paths", s.config.Paths),
+		attribute.Int64("logmonster.interval_ms", s.config.Interval.Milliseconds()),
+	)
+	defer func() { endSpan(span, err) }()
+
+	startTime := time.Now()
+	_ = s.auditLog.Record(audit.Event{Type: audit.ScanStarted, Paths: s.config.Paths})
 
 	// Take first snapshot
 	snap1, err := s.TakeSnapshot(ctx)
 	if err != nil {
 		return nil, err
 	}
-	result.Snapshot1 = snap1
 
 	// Wait for interval
+	_, waitSpan := s.startSpan(ctx, "Wait")
 	select {
 	case <-ctx.Done():
+		endSpan(waitSpan, ctx.Err())
 		return nil, ctx.Err()
 	case <-time.After(s.config.Interval):
 	}
+	waitSpan.End()
 
 	// Take second snapshot
 	snap2, err := s.TakeSnapshot(ctx)
 	if err != nil {
 		return nil, err
 	}
-	result.Snapshot2 = snap2
+
+	_, growthSpan := s.startSpan(ctx, "CalculateGrowth")
+	result = s.compare(snap1, snap2, warmup)
+	growthSpan.SetAttributes(attribute.Int("logmonster.growing_files", len(result.GrowingFiles)))
+	growthSpan.End()
+
+	result.StartTime = startTime
 	result.EndTime = time.Now()
+	result.Interval = s.config.Interval
+
+	return result, nil
+}
 
-	// Calculate growth
-	result.GrowingFiles = s.CalculateGrowth(snap1, snap2)
+// ScanWithSnapshots calculates growth between two existing snapshots without
+// touching the filesystem or waiting for the interval. If the snapshots were
+// taken over different scan paths, a warning is added to the result; use
+// RestrictToCommonPaths first to compare only what both cover.
+func (s *Scanner) ScanWithSnapshots(snap1, snap2 *types.Snapshot) *types.ScanResult {
+	return s.compare(snap1, snap2, false)
+}
 
-	// Calculate total growth
-	for _, g := range result.GrowingFiles {
-		result.TotalGrowth += g.GrowthBytes
+// compare builds a ScanResult for two snapshots.
+func (s *Scanner) compare(snap1, snap2 *types.Snapshot, warmup bool) *types.ScanResult {
+	result := &types.ScanResult{
+		StartTime: snap1.Timestamp,
+		EndTime:   snap2.Timestamp,
+		Interval:  snap2.Timestamp.Sub(snap1.Timestamp),
+		Snapshot1: snap1,
+		Snapshot2: snap2,
+		Paths:     s.config.Paths,
 	}
 
-	return result, nil
+	// Total growth counts every flagged file, not just the TopN returned
+	result.GrowingFiles, result.TotalGrowth = s.calculateGrowth(snap1, snap2, warmup)
+
+	if s.config.ReportRemoved {
+		result.RemovedFiles = RemovedFiles(snap1, snap2)
+	}
+
+	if err := CheckSnapshotPaths(snap1, snap2); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+
+	if n := max(snap1.LongPathsSkipped, snap2.LongPathsSkipped); n > 0 {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("%d paths skipped: longer than the system path limit", n))
+	}
+
+	return result
 }
 
 // TakeSnapshot takes a snapshot of all files in the configured paths.
-func (s *Scanner) TakeSnapshot(ctx context.Context) (*types.Snapshot, error) {
+// Per-file problems are not errors: files that can't be stat'd and
+// directories that can't be read are skipped. The only error returned is
+// ctx.Err() when the snapshot was cancelled before it completed.
+func (s *Scanner) TakeSnapshot(ctx context.Context) (_ *types.Snapshot, err error) {
+	ctx, span := s.startSpan(ctx, "TakeSnapshot")
+	defer func() { endSpan(span, err) }()
+
 	snapshot := &types.Snapshot{
 		Timestamp: time.Now(),
-		Files:     make(map[string]types.FileInfo),
+		Files:     make(map[string]types.FileInfo, s.lastCount.Load()),
+		Metadata:  s.metadata(),
+		Mounts:    s.config.mountUsage(s.basePaths()),
 	}
 
-	fileChan := make(chan string, 1000)
+	fileChan := make(chan walkEntry, 1000)
 	resultChan := make(chan types.FileInfo, 1000)
-	errChan := make(chan error, 1)
+	var vanished vanishedDirs
+
+	var longPaths atomic.Int64
+	config := s.config
+	config.longPaths = &longPaths
+	config.self = newSelfFilter(s.config.SelfPaths).withOpenFiles()
+	config.mounts = readMountTable()
 
 	var wg sync.WaitGroup
 
-	// Start workers
+	// Start workers. On cancellation they stop statting but keep draining
+	// fileChan so the walker can never block on a send.
 	for i := 0; i < s.config.WorkerCount; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for path := range fileChan {
+			for entry := range fileChan {
+				if ctx.Err() != nil {
+					continue
+				}
+				// Skip files we can't stat (permission denied, deleted, etc.)
+				info, ok := s.statWalked(config, entry, &vanished)
+				if !ok {
+					continue
+				}
 				select {
+				case resultChan <- info:
 				case <-ctx.Done():
-					return
-				default:
-					info, err := s.statFile(path)
-					if err != nil {
-						// Skip files we can't stat (permission denied, deleted, etc.)
-						continue
-					}
-					resultChan <- info
 				}
 			}
 		}()
@@ -126,86 +389,130 @@ func (s *Scanner) TakeSnapshot(ctx context.Context) (*types.Snapshot, error) {
 	// Walk directories and feed file paths
 	go func() {
 		defer close(fileChan)
-		for _, basePath := range s.config.Paths {
+		for _, basePath := range s.basePaths() {
 			select {
 			case <-ctx.Done():
 				return
 			default:
-				s.walkDirectory(ctx, basePath, fileChan, 0)
+				walkDirectory(ctx, config, basePath, fileChan)
 			}
 		}
 	}()
 
-	// Collect results
+	// Close resultChan once the walker has finished and every worker exited
 	go func() {
 		wg.Wait()
 		close(resultChan)
 	}()
 
-	// Process results
+	// Process results. This always drains resultChan to completion, so no
+	// goroutine outlives TakeSnapshot. Directory sizes count every file,
+	// including those the MaxFiles cap drops.
+	var dirs *dirSizer
+	if s.config.TrackDirs {
+		dirs = newDirSizer(s.basePaths())
+	}
+	var largest *largestFiles
+	var collected map[string]bool // under the cap, the snapshot doesn't hold every path
+	if s.config.MaxFiles > 0 {
+		largest = &largestFiles{n: s.config.MaxFiles}
+		collected = make(map[string]bool)
+	}
+	collect := func(info types.FileInfo) {
+		if dirs != nil {
+			dirs.add(info)
+		}
+		if largest != nil {
+			collected[info.Path] = true
+			largest.add(info)
+			return
+		}
+		addToSnapshot(snapshot, info)
+	}
+	seen := func(path string) bool {
+		if largest != nil {
+			return collected[path]
+		}
+		_, ok := snapshot.Files[path]
+		return ok
+	}
 	for info := range resultChan {
-		snapshot.Files[info.Path] = info
-		if !info.IsDir {
-			snapshot.TotalSize += info.Size
-			snapshot.FileCount++
+		collect(info)
+	}
+	// Files recovered by a rescan compete for the cap like any other
+	if len(vanished.dirs) > 0 {
+		s.rescan(ctx, config, &vanished, seen, collect)
+	}
+	if largest != nil {
+		for _, info := range largest.h {
+			addToSnapshot(snapshot, info)
 		}
+		snapshot.Truncated = largest.evicted
 	}
+	snapshot.LongPathsSkipped = int(longPaths.Load())
 
-	select {
-	case err := <-errChan:
+	// A cancelled walk yields an incomplete snapshot; don't pass it off as whole
+	if err := ctx.Err(); err != nil {
 		return nil, err
-	default:
-	}
-
-	return snapshot, nil
-}
-
-// walkDirectory recursively walks a directory and sends file paths to the channel.
-func (s *Scanner) walkDirectory(ctx context.Context, path string, fileChan chan<- string, depth int) {
-	if s.config.MaxDepth > 0 && depth > s.config.MaxDepth {
-		return
 	}
+	s.lastCount.Store(int64(len(snapshot.Files)))
 
-	select {
-	case <-ctx.Done():
-		return
-	default:
+	if dirs != nil {
+		snapshot.Dirs = dirs.sizes
 	}
 
-	entries, err := os.ReadDir(path)
-	if err != nil {
-		return // Skip directories we can't read
-	}
+	span.SetAttributes(
+		attribute.Int("logmonster.file_count", snapshot.FileCount),
+		attribute.Int64("logmonster.total_bytes", snapshot.TotalSize),
+		attribute.Bool("logmonster.truncated", snapshot.Truncated),
+	)
 
-	for _, entry := range entries {
-		fullPath := filepath.Join(path, entry.Name())
+	return snapshot, nil
+}
 
-		// Skip symlinks if configured
-		if entry.Type()&os.ModeSymlink != 0 && !s.config.FollowSymlinks {
-			continue
-		}
+// addToSnapshot records a file in the snapshot and its totals.
+func addToSnapshot(snapshot *types.Snapshot, info types.FileInfo) {
+	snapshot.Files[info.Path] = info
+	if !info.IsDir {
+		snapshot.TotalSize += info.Size
+		snapshot.FileCount++
+	}
+}
 
-		// Check exclude patterns
-		if s.isExcluded(entry.Name()) {
-			continue
+// walkDirectory walks a directory tree and sends file paths to the channel.
+func walkDirectory(ctx context.Context, c Config, path string, fileChan chan<- walkEntry) {
+	walkTree(ctx, c, path, 0, func(fullPath string, typ os.FileMode) error {
+		select {
+		case fileChan <- walkEntry{path: fullPath, typ: typ}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
+	})
+}
 
-		if entry.IsDir() {
-			s.walkDirectory(ctx, fullPath, fileChan, depth+1)
-		} else {
-			select {
-			case fileChan <- fullPath:
-			case <-ctx.Done():
-				return
-			}
+// matchExcludeDir checks a directory path against excluded directory
+// prefixes. Prefixes match whole path components only.
+func matchExcludeDir(dirs []string, path string) bool {
+	for _, dir := range dirs {
+		if pathHasPrefix(path, filepath.Clean(dir)) {
+			return true
 		}
 	}
+	return false
 }
 
-// isExcluded checks if a filename matches any exclude pattern.
-func (s *Scanner) isExcluded(name string) bool {
-	for _, pattern := range s.config.ExcludePatterns {
-		matched, _ := filepath.Match(pattern, name)
+// matchExclude checks a path against exclude patterns. Patterns containing a
+// path separator are matched against the full path; bare patterns are
+// matched against the base name only.
+func matchExclude(patterns []string, path string) bool {
+	name := filepath.Base(path)
+	for _, pattern := range patterns {
+		target := name
+		if strings.ContainsRune(pattern, filepath.Separator) {
+			target = path
+		}
+		matched, _ := filepath.Match(pattern, target)
 		if matched {
 			return true
 		}
@@ -213,65 +520,239 @@ func (s *Scanner) isExcluded(name string) bool {
 	return false
 }
 
-// statFile returns file information for a path.
+// statFile returns file information for a path. With ResolveSymlinks set,
+// the returned Path is the symlink-free real path; broken links fail.
 func (s *Scanner) statFile(path string) (types.FileInfo, error) {
-	info, err := os.Stat(path)
+	info, _, err := s.statFileMode(path)
+	return info, err
+}
+
+// statFileMode is statFile that also returns the file's mode.
+func (s *Scanner) statFileMode(path string) (types.FileInfo, os.FileMode, error) {
+	if s.config.ResolveSymlinks {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return types.FileInfo{}, 0, err
+		}
+		path = resolved
+	}
+
+	info, err := statRetry(s.config.fs(), path)
 	if err != nil {
-		return types.FileInfo{}, err
+		return types.FileInfo{}, 0, err
 	}
 
-	return types.FileInfo{
+	return fileInfoFromOS(path, info), info.Mode(), nil
+}
+
+// maxStatRetries bounds how often a stat interrupted by a signal is retried.
+const maxStatRetries = 5
+
+// statRetry stats path, retrying transient EINTR and EAGAIN failures so
+// that signal delivery can't silently drop files from a snapshot.
+func statRetry(fsys FS, path string) (os.FileInfo, error) {
+	for attempt := 0; ; attempt++ {
+		info, err := fsys.Stat(path)
+		if err == nil || attempt >= maxStatRetries || !isTransient(err) {
+			return info, err
+		}
+	}
+}
+
+// isTransient reports whether err is worth retrying.
+func isTransient(err error) bool {
+	return errors.Is(err, syscall.EINTR) || errors.Is(err, syscall.EAGAIN)
+}
+
+// fileInfoFromOS converts an os.FileInfo into a types.FileInfo.
+func fileInfoFromOS(path string, info os.FileInfo) types.FileInfo {
+	fi := types.FileInfo{
 		Path:       path,
 		Size:       info.Size(),
 		ModTime:    info.ModTime(),
 		IsDir:      info.IsDir(),
-		Permission: uint32(info.Mode().Perm()),
-	}, nil
+		Permission: unixMode(info.Mode()),
+	}
+	if _, ino, uid, ok := statIDs(info); ok {
+		fi.Inode = ino
+		fi.UID = uid
+	}
+	return fi
 }
 
-// CalculateGrowth calculates file growth between two snapshots.
-func (s *Scanner) CalculateGrowth(snap1, snap2 *types.Snapshot) []types.FileGrowth {
-	interval := snap2.Timestamp.Sub(snap1.Timestamp)
-	if interval <= 0 {
-		interval = time.Second // Prevent division by zero
+// unixMode converts a FileMode to traditional Unix permission bits, keeping
+// the setuid, setgid and sticky bits that FileMode stores out of band.
+func unixMode(mode os.FileMode) uint32 {
+	perm := uint32(mode.Perm())
+	if mode&os.ModeSetuid != 0 {
+		perm |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		perm |= 02000
 	}
+	if mode&os.ModeSticky != 0 {
+		perm |= 01000
+	}
+	return perm
+}
 
-	var growing []types.FileGrowth
+// RemovedFiles returns files present in snap1 but missing from snap2, with
+// their last-known size, largest first. Deleted and rotated-away files both
+// show up here. A Truncated snap2 may be missing files that still exist, so
+// nothing is reported for it.
+func RemovedFiles(snap1, snap2 *types.Snapshot) []types.FileInfo {
+	if snap2.Truncated {
+		return nil
+	}
+
+	var removed []types.FileInfo
+	for path, info := range snap1.Files {
+		if info.IsDir {
+			continue
+		}
+		if _, ok := snap2.Files[path]; !ok {
+			removed = append(removed, info)
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Size > removed[j].Size
+	})
+
+	return removed
+}
+
+// CalculateGrowth calculates file growth between two snapshots. Directory
+// entries in Files are ignored; with TrackDirs set, directories whose
+// aggregate size in Dirs grew past the thresholds beyond what reported
+// files account for are included with IsDir set.
+//
+// New files, missing from snap1, are reported at their full size unless
+// warmup is set. Pass warmup for the first comparison of a monitoring loop,
+// where snap1 is no real baseline yet, to avoid a storm of false positives
+// at startup. Files present in both snapshots are judged as usual.
+//
+// With PendingNewFiles set, a new file below the thresholds is carried to
+// the next call and judged there as new again, by its full size over the
+// time since it appeared, so a file created tiny that then explodes is
+// caught. Files appearing during a warmup call are not carried.
+//
+// If either snapshot is Truncated, a file missing from snap1 may simply have
+// been dropped by the MaxFiles cap, so only files present in both are
+// compared.
+func (s *Scanner) CalculateGrowth(snap1, snap2 *types.Snapshot, warmup bool) []types.FileGrowth {
+	growing, _ := s.calculateGrowth(snap1, snap2, warmup)
+	return growing
+}
+
+// calculateGrowth is CalculateGrowth that also returns the total growth of
+// every flagged file, including those beyond TopN.
+func (s *Scanner) calculateGrowth(snap1, snap2 *types.Snapshot, warmup bool) ([]types.FileGrowth, int64) {
+	partial := snap1.Truncated || snap2.Truncated
+	interval := snapshotInterval(snap1, snap2)
+
+	// Keep only the top N in a heap rather than sorting every match
+	top := &topGrowth{n: s.config.TopN}
+
+	minBytes, minRate := s.Thresholds()
+	calc := s.calculator()
+
+	// Growth of reported files, per ancestor directory
+	var claimed map[string]int64
+	if s.config.TrackDirs {
+		claimed = make(map[string]int64)
+	}
+
+	allowlist := s.allowlist
+	if allowlist != nil {
+		allowlist.mu.Lock()
+		defer allowlist.mu.Unlock()
+	}
+
+	// New files below the thresholds, from the last call and for the next
+	var pending, nextPending map[string]time.Time
+	if s.config.PendingNewFiles {
+		s.pendingMu.Lock()
+		defer s.pendingMu.Unlock()
+		pending = s.pending
+		nextPending = make(map[string]time.Time)
+	}
 
 	for path, info2 := range snap2.Files {
+		if info2.IsDir {
+			continue
+		}
+
 		info1, exists := snap1.Files[path]
+		if exists && allowlist != nil && allowlist.skip(path, info2.Size-info1.Size) {
+			continue
+		}
 		if !exists {
+			if warmup || partial {
+				continue
+			}
 			// New file - count entire size as growth
-			if info2.Size >= s.config.ThresholdBytes {
-				growing = append(growing, types.FileGrowth{
-					Path:        path,
-					InitialSize: 0,
-					FinalSize:   info2.Size,
-					GrowthBytes: info2.Size,
-					GrowthRate:  float64(info2.Size) / interval.Seconds(),
-					Interval:    interval,
-				})
+			if g, ok := calc.Evaluate(path, types.FileInfo{}, info2, interval); ok {
+				if claimed != nil {
+					claimAncestors(claimed, path, g.GrowthBytes)
+				}
+				top.add(g)
+			} else if nextPending != nil {
+				nextPending[path] = snap1.Timestamp
 			}
 			continue
 		}
 
 		growth := info2.Size - info1.Size
-		if growth >= s.config.ThresholdBytes {
-			growing = append(growing, types.FileGrowth{
-				Path:        path,
-				InitialSize: info1.Size,
-				FinalSize:   info2.Size,
-				GrowthBytes: growth,
-				GrowthRate:  float64(growth) / interval.Seconds(),
-				Interval:    interval,
-			})
+		fileInterval := interval
+		if since, ok := pending[path]; ok && snap2.Timestamp.After(since) {
+			// Appeared last call below the thresholds: judge it over its whole life
+			info1, fileInterval = types.FileInfo{}, snap2.Timestamp.Sub(since)
+		}
+
+		g, ok := calc.Evaluate(path, info1, info2, fileInterval)
+		if allowlist != nil {
+			allowlist.learn(path, growth, ok)
+		}
+		if ok {
+			if claimed != nil {
+				claimAncestors(claimed, path, g.GrowthBytes)
+			}
+			top.add(g)
 		}
 	}
 
-	// Sort by growth rate descending
-	sort.Slice(growing, func(i, j int) bool {
-		return growing[i].GrowthRate > growing[j].GrowthRate
-	})
+	if allowlist != nil && !snap2.Truncated {
+		allowlist.forget(snap2)
+	}
+	if f, ok := calc.Strategy.(forgetter); ok && !snap2.Truncated {
+		f.forget(snap2)
+	}
 
-	return growing
+	if nextPending != nil {
+		s.pending = nextPending
+	}
+
+	if claimed != nil {
+		for _, g := range dirGrowth(snap1, snap2, interval, minBytes, minRate, claimed, warmup) {
+			top.add(g)
+		}
+	}
+
+	// Sorted by growth rate descending. Annotate only the files kept.
+	growing := top.sorted()
+	selfDirs := newSelfFilter(s.config.SelfPaths)
+	mounts := snapshotMounts(snap2)
+	for i := range growing {
+		g := &growing[i]
+		g.NormalizedRate = s.normalizedRate(g.GrowthRate, interval)
+		if g.IsDir {
+			g.SelfGenerated = selfDirs.matches(g.Path)
+			g.FilesystemType = mounts.typeOf(g.Path)
+		}
+	}
+
+	s.auditFlagged(growing)
+
+	return growing, top.total
 }
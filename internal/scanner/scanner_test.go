@@ -0,0 +1,387 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+	"go.uber.org/goleak"
+)
+
+// makeTree creates dirs directories of files empty files each under a
+// temporary directory and returns its path.
+func makeTree(tb testing.TB, dirs, files int) string {
+	tb.Helper()
+	root := tb.TempDir()
+	for d := 0; d < dirs; d++ {
+		dir := filepath.Join(root, fmt.Sprintf("d%03d", d))
+		if err := os.Mkdir(dir, 0755); err != nil {
+			tb.Fatal(err)
+		}
+		for f := 0; f < files; f++ {
+			if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("f%04d.log", f)), nil, 0644); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	return root
+}
+
+func BenchmarkTakeSnapshotWorkers(b *testing.B) {
+	root := makeTree(b, 100, 200)
+
+	counts := []int{1, 4}
+	if n := runtime.NumCPU(); n != 1 && n != 4 {
+		counts = append(counts, n)
+	}
+
+	for _, workers := range counts {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			config := DefaultConfig()
+			config.Paths = []string{root}
+			config.WorkerCount = workers
+			s := New(config)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.TakeSnapshot(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// treeFS is an in-memory tree of dirs directories of files empty files
+// each under /tree, cheap enough to walk that a benchmark measures the
+// snapshot itself rather than the filesystem.
+type treeFS struct {
+	dirs map[string][]os.DirEntry
+	info map[string]os.FileInfo
+}
+
+// fakeInfo is an os.FileInfo for treeFS.
+type fakeInfo struct {
+	name string
+	mode os.FileMode
+}
+
+func (i fakeInfo) Name() string       { return i.name }
+func (i fakeInfo) Size() int64        { return 0 }
+func (i fakeInfo) Mode() os.FileMode  { return i.mode }
+func (i fakeInfo) ModTime() time.Time { return time.Time{} }
+func (i fakeInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fakeInfo) Sys() any           { return nil }
+
+func newTreeFS(dirs, files int) *treeFS {
+	t := &treeFS{dirs: make(map[string][]os.DirEntry), info: make(map[string]os.FileInfo)}
+	add := func(dir string, info fakeInfo) {
+		t.dirs[dir] = append(t.dirs[dir], fs.FileInfoToDirEntry(info))
+		t.info[filepath.Join(dir, info.name)] = info
+	}
+	t.info["/tree"] = fakeInfo{name: "tree", mode: fs.ModeDir}
+	for d := 0; d < dirs; d++ {
+		dir := fakeInfo{name: fmt.Sprintf("d%03d", d), mode: fs.ModeDir}
+		add("/tree", dir)
+		for f := 0; f < files; f++ {
+			add(filepath.Join("/tree", dir.name), fakeInfo{name: fmt.Sprintf("f%04d.log", f)})
+		}
+	}
+	return t
+}
+
+func (t *treeFS) ReadDir(name string) ([]os.DirEntry, error) {
+	if _, ok := t.info[name]; !ok {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: fs.ErrNotExist}
+	}
+	return t.dirs[name], nil
+}
+
+func (t *treeFS) Stat(name string) (os.FileInfo, error) {
+	info, ok := t.info[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
+// BenchmarkTakeSnapshotPresize measures sizing the Files map from the last
+// snapshot's count against growing it from empty, over 500k files.
+func BenchmarkTakeSnapshotPresize(b *testing.B) {
+	fsys := newTreeFS(500, 1000)
+
+	for _, hint := range []bool{false, true} {
+		b.Run(fmt.Sprintf("hint=%v", hint), func(b *testing.B) {
+			config := DefaultConfig()
+			config.Paths = []string{"/tree"}
+			config.FS = fsys
+			s := New(config)
+			if _, err := s.TakeSnapshot(context.Background()); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !hint {
+					s.lastCount.Store(0)
+				}
+				if _, err := s.TakeSnapshot(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// dirCacheFS reuses a directory's listing while its mtime is unchanged,
+// trading a ReadDir of each quiet directory for a Stat. File stats are
+// untouched, since appending to a file doesn't change its directory's mtime.
+type dirCacheFS struct {
+	FS
+
+	mu    sync.Mutex
+	cache map[string]cachedDir // nil = always read the directory
+}
+
+type cachedDir struct {
+	mtime   time.Time
+	entries []os.DirEntry
+}
+
+func (c *dirCacheFS) ReadDir(name string) ([]os.DirEntry, error) {
+	if c.cache == nil {
+		return c.FS.ReadDir(name)
+	}
+	info, err := c.FS.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	cached, ok := c.cache[name]
+	c.mu.Unlock()
+	if ok && cached.mtime.Equal(info.ModTime()) {
+		return cached.entries, nil
+	}
+
+	entries, err := c.FS.ReadDir(name)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.cache[name] = cachedDir{mtime: info.ModTime(), entries: entries}
+	c.mu.Unlock()
+	return entries, nil
+}
+
+// BenchmarkTakeSnapshotDirCache measures caching directory listings by
+// mtime on a quiet tree of 10k files in 200 directories. The per-file stats
+// dominate either way, which is why snapshots don't cache listings.
+func BenchmarkTakeSnapshotDirCache(b *testing.B) {
+	root := makeTree(b, 200, 50)
+
+	for _, cached := range []bool{false, true} {
+		b.Run(fmt.Sprintf("cached=%v", cached), func(b *testing.B) {
+			// Both cases go through the wrapper so neither records mounts
+			fsys := &dirCacheFS{FS: OSFS}
+			if cached {
+				fsys.cache = make(map[string]cachedDir)
+			}
+			config := DefaultConfig()
+			config.Paths = []string{root}
+			config.FS = fsys
+			s := New(config)
+			if _, err := s.TakeSnapshot(context.Background()); err != nil {
+				b.Fatal(err)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.TakeSnapshot(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// cancelFS cancels a context on the first Stat, so a snapshot is cancelled
+// while the walk is still feeding workers.
+type cancelFS struct {
+	FS
+	once   sync.Once
+	cancel context.CancelFunc
+}
+
+func (f *cancelFS) Stat(name string) (os.FileInfo, error) {
+	f.once.Do(f.cancel)
+	return f.FS.Stat(name)
+}
+
+func TestTakeSnapshotCancelledLeaksNoGoroutines(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config := DefaultConfig()
+	config.Paths = []string{makeTree(t, 20, 200)}
+	config.WorkerCount = 4
+	config.FS = &cancelFS{FS: OSFS, cancel: cancel}
+
+	snapshot, err := New(config).TakeSnapshot(ctx)
+	if err != context.Canceled {
+		t.Fatalf("TakeSnapshot error = %v, want %v", err, context.Canceled)
+	}
+	if snapshot != nil {
+		t.Errorf("TakeSnapshot returned a snapshot of %d files after cancellation", len(snapshot.Files))
+	}
+}
+
+// brokenFS fails Stat and ReadDir with EACCES for the names in broken.
+type brokenFS struct {
+	FS
+	broken map[string]bool
+}
+
+func (f brokenFS) Stat(name string) (os.FileInfo, error) {
+	if f.broken[name] {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: syscall.EACCES}
+	}
+	return f.FS.Stat(name)
+}
+
+func (f brokenFS) ReadDir(name string) ([]os.DirEntry, error) {
+	if f.broken[name] {
+		return nil, &fs.PathError{Op: "readdirent", Path: name, Err: syscall.EACCES}
+	}
+	return f.FS.ReadDir(name)
+}
+
+func TestTakeSnapshotErrors(t *testing.T) {
+	base := FromIOFS(fstest.MapFS{
+		"var/log/ok.log":          {Data: []byte("ok")},
+		"var/log/denied.log":      {Data: []byte("denied")},
+		"var/log/private/app.log": {Data: []byte("private")},
+	})
+	fsys := brokenFS{FS: base, broken: map[string]bool{
+		"/var/log/denied.log": true,
+		"/var/log/private":    true,
+	}}
+
+	newScanner := func() *Scanner {
+		config := DefaultConfig()
+		config.Paths = []string{"/var/log"}
+		config.FS = fsys
+		return New(config)
+	}
+
+	t.Run("per-file problems are skipped", func(t *testing.T) {
+		snapshot, err := newScanner().TakeSnapshot(context.Background())
+		if err != nil {
+			t.Fatalf("TakeSnapshot error = %v, want nil", err)
+		}
+		var got []string
+		for path := range snapshot.Files {
+			got = append(got, path)
+		}
+		if want := []string{"/var/log/ok.log"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("snapshot files = %v, want %v", got, want)
+		}
+	})
+
+	cancelled := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+		want error
+	}{
+		{"cancelled", func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}, context.Canceled},
+		{"deadline passed", func() (context.Context, context.CancelFunc) {
+			return context.WithDeadline(context.Background(), time.Now())
+		}, context.DeadlineExceeded},
+	}
+
+	for _, tt := range cancelled {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			cancel()
+
+			snapshot, err := newScanner().TakeSnapshot(ctx)
+			if err != tt.want {
+				t.Errorf("TakeSnapshot error = %v, want %v", err, tt.want)
+			}
+			if snapshot != nil {
+				t.Errorf("TakeSnapshot returned a snapshot with error %v", err)
+			}
+		})
+	}
+}
+
+// snapshotOf builds a snapshot at t seconds holding files of the given sizes.
+func snapshotOf(t int, sizes map[string]int64) *types.Snapshot {
+	snap := &types.Snapshot{
+		Timestamp: time.Unix(int64(t), 0),
+		Files:     make(map[string]types.FileInfo, len(sizes)),
+	}
+	for path, size := range sizes {
+		addToSnapshot(snap, types.FileInfo{Path: path, Size: size})
+	}
+	return snap
+}
+
+func TestCalculateGrowthTruncated(t *testing.T) {
+	config := DefaultConfig()
+	config.ThresholdBytes = 100
+	s := New(config)
+
+	tests := []struct {
+		name       string
+		truncated1 bool
+		truncated2 bool
+		want       []string
+	}{
+		{"complete", false, false, []string{"/log/big", "/log/grew"}},
+		{"first truncated", true, false, []string{"/log/grew"}},
+		{"second truncated", false, true, []string{"/log/grew"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snap1 := snapshotOf(0, map[string]int64{"/log/grew": 10})
+			snap2 := snapshotOf(10, map[string]int64{"/log/grew": 500, "/log/big": 1000})
+			snap1.Truncated, snap2.Truncated = tt.truncated1, tt.truncated2
+
+			var got []string
+			for _, g := range s.CalculateGrowth(snap1, snap2, false) {
+				got = append(got, g.Path)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CalculateGrowth = %v, want %v", got, tt.want)
+			}
+
+			got = nil
+			for _, g := range CompareSnapshots(snap1, snap2, 100) {
+				got = append(got, g.Path)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompareSnapshots = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
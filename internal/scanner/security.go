@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// sensitivePaths hold logs that may contain credentials or login records
+// and should not be readable by every user.
+var sensitivePaths = []string{
+	"/var/log/auth.log",
+	"/var/log/secure",
+	"/var/log/audit",
+	"/var/log/btmp",
+	"/var/log/sudo.log",
+}
+
+// FindInsecureFiles returns files in snapshot whose permissions are a
+// security concern, sorted by path: world-writable files, regular files with
+// setuid or setgid set, and root-owned files under sensitivePaths that are
+// world-readable.
+func (s *Scanner) FindInsecureFiles(snapshot *types.Snapshot) []types.FileInfo {
+	var insecure []types.FileInfo
+	for _, info := range snapshot.Files {
+		if info.IsDir {
+			continue
+		}
+		if insecureFile(info) {
+			insecure = append(insecure, info)
+		}
+	}
+
+	sort.Slice(insecure, func(i, j int) bool {
+		return insecure[i].Path < insecure[j].Path
+	})
+
+	return insecure
+}
+
+// insecureFile reports whether a regular file's permissions are a concern.
+func insecureFile(info types.FileInfo) bool {
+	switch {
+	case info.Permission&0002 != 0:
+		return true
+	case info.Permission&(04000|02000) != 0:
+		return true
+	case info.UID == 0 && info.Permission&0004 != 0 && sensitive(info.Path):
+		return true
+	}
+	return false
+}
+
+// sensitive reports whether path is, lies under, or is a rotation (e.g.
+// "auth.log.1") of one of sensitivePaths.
+func sensitive(path string) bool {
+	for _, p := range sensitivePaths {
+		if pathHasPrefix(path, p) || strings.HasPrefix(path, p+".") {
+			return true
+		}
+	}
+	return false
+}
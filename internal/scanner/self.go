@@ -0,0 +1,76 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// selfFilter recognizes files written by logmonster itself.
+type selfFilter struct {
+	dirs  []string
+	files map[string]bool
+}
+
+// newSelfFilter builds a filter from the configured self paths.
+func newSelfFilter(dirs []string) *selfFilter {
+	f := &selfFilter{}
+	for _, dir := range dirs {
+		if abs, err := filepath.Abs(dir); err == nil {
+			f.dirs = append(f.dirs, abs)
+		}
+	}
+	return f
+}
+
+// withOpenFiles adds the files this process currently has open, so they
+// are only worth reading when a snapshot is taken.
+func (f *selfFilter) withOpenFiles() *selfFilter {
+	f.files = ownOpenFiles()
+	return f
+}
+
+// matches reports whether path was written by logmonster.
+func (f *selfFilter) matches(path string) bool {
+	if f.files[path] {
+		return true
+	}
+	for _, dir := range f.dirs {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownOpenFiles returns the files this process has open. It is empty on
+// platforms without /proc.
+func ownOpenFiles() map[string]bool {
+	files := make(map[string]bool)
+
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return files
+	}
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name()))
+		if err != nil || !strings.HasPrefix(link, "/") {
+			continue
+		}
+		files[link] = true
+	}
+
+	return files
+}
+
+// label tags info with what is known about the file when the snapshot is
+// taken, so comparing snapshots later never has to look at the host.
+func (c Config) label(info types.FileInfo) types.FileInfo {
+	if c.self != nil {
+		info.SelfGenerated = c.self.matches(info.Path)
+	}
+	info.FilesystemType = c.mounts.typeOf(info.Path)
+	return info
+}
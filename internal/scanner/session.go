@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"sort"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// SessionAggregator merges the results of repeated scans into a summary of
+// the whole monitoring session.
+type SessionAggregator struct {
+	report types.SessionReport
+	files  map[string]*types.SessionFile
+}
+
+// NewSessionAggregator creates an empty aggregator.
+func NewSessionAggregator() *SessionAggregator {
+	return &SessionAggregator{files: make(map[string]*types.SessionFile)}
+}
+
+// Add ingests one scan result.
+func (a *SessionAggregator) Add(result *types.ScanResult) {
+	if result == nil {
+		return
+	}
+
+	if a.report.Scans == 0 || result.StartTime.Before(a.report.Start) {
+		a.report.Start = result.StartTime
+	}
+	if result.EndTime.After(a.report.End) {
+		a.report.End = result.EndTime
+	}
+	a.report.Scans++
+	a.report.TotalGrowth += result.TotalGrowth
+
+	for _, g := range result.GrowingFiles {
+		f, ok := a.files[g.Path]
+		if !ok {
+			f = &types.SessionFile{Path: g.Path, FirstSeen: result.EndTime}
+			a.files[g.Path] = f
+		}
+		f.Occurrences++
+		f.TotalGrowth += g.GrowthBytes
+		if g.GrowthRate > f.PeakRate {
+			f.PeakRate = g.GrowthRate
+		}
+		f.LastSeen = result.EndTime
+	}
+}
+
+// Report returns the session summary so far. Files are ordered by how often
+// they were flagged, then by cumulative growth.
+func (a *SessionAggregator) Report() types.SessionReport {
+	report := a.report
+	report.Files = make([]types.SessionFile, 0, len(a.files))
+	for _, f := range a.files {
+		report.Files = append(report.Files, *f)
+	}
+
+	sort.Slice(report.Files, func(i, j int) bool {
+		fi, fj := report.Files[i], report.Files[j]
+		if fi.Occurrences != fj.Occurrences {
+			return fi.Occurrences > fj.Occurrences
+		}
+		if fi.TotalGrowth != fj.TotalGrowth {
+			return fi.TotalGrowth > fj.TotalGrowth
+		}
+		return fi.Path < fj.Path
+	})
+
+	return report
+}
@@ -0,0 +1,32 @@
+package scanner
+
+import (
+	"sort"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// TopFilesBySize returns the n largest files in a snapshot, sorted by size
+// descending. All files are returned when n <= 0.
+func (s *Scanner) TopFilesBySize(snapshot *types.Snapshot, n int) []types.FileInfo {
+	files := make([]types.FileInfo, 0, len(snapshot.Files))
+	for _, info := range snapshot.Files {
+		if info.IsDir {
+			continue
+		}
+		files = append(files, info)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+
+	if n > 0 && len(files) > n {
+		files = files[:n]
+	}
+
+	return files
+}
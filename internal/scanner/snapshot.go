@@ -1,90 +1,248 @@
 package scanner
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
-	"time"
+	"path/filepath"
+	"syscall"
 
 	"github.com/thiruk/logmonster/pkg/types"
 )
 
-// SnapshotStore handles saving and loading snapshots to disk.
-type SnapshotStore struct {
+// Backend stores snapshot files by name.
+type Backend interface {
+	Put(name string, r io.Reader) error
+	Get(name string) (io.ReadCloser, error)
+	List() ([]string, error)
+	Delete(name string) error
+}
+
+// FSBackend stores snapshots as files under a base directory.
+type FSBackend struct {
 	basePath string
 }
 
-// NewSnapshotStore creates a new snapshot store.
+// NewFSBackend creates a filesystem backend rooted at basePath.
+// Absolute names are used as-is.
+func NewFSBackend(basePath string) *FSBackend {
+	return &FSBackend{basePath: basePath}
+}
+
+// BasePath returns the directory snapshots are stored in.
+func (b *FSBackend) BasePath() string {
+	return b.basePath
+}
+
+// Put writes the contents of r to the named file. The data goes to a
+// temporary file that is renamed into place, so a failed write (for
+// example on a full disk) leaves any previous file intact and no partial
+// file behind.
+func (b *FSBackend) Put(name string, r io.Reader) error {
+	path := b.path(name)
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0644); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+// Get opens the named file for reading.
+func (b *FSBackend) Get(name string) (io.ReadCloser, error) {
+	return os.Open(b.path(name))
+}
+
+// List returns the names of regular files in the base directory.
+func (b *FSBackend) List() ([]string, error) {
+	dir := b.basePath
+	if dir == "" {
+		dir = "."
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// Delete removes the named file.
+func (b *FSBackend) Delete(name string) error {
+	return os.Remove(b.path(name))
+}
+
+func (b *FSBackend) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(b.basePath, name)
+}
+
+// ErrDiskFull is returned by Save when the backend ran out of space.
+var ErrDiskFull = errors.New("disk full while saving snapshot")
+
+// DefaultFallbackTopN is how many of the largest files a fallback snapshot
+// keeps.
+const DefaultFallbackTopN = 100
+
+// SnapshotStore handles saving and loading snapshots.
+type SnapshotStore struct {
+	backend Backend
+
+	// fallback receives a minimal snapshot when backend is full.
+	fallback     Backend
+	fallbackTopN int
+}
+
+// NewSnapshotStore creates a new snapshot store on the local filesystem.
 func NewSnapshotStore(basePath string) *SnapshotStore {
-	return &SnapshotStore{basePath: basePath}
+	return NewSnapshotStoreWithBackend(NewFSBackend(basePath))
+}
+
+// NewSnapshotStoreWithBackend creates a snapshot store using the given backend.
+func NewSnapshotStoreWithBackend(backend Backend) *SnapshotStore {
+	return &SnapshotStore{backend: backend}
 }
 
-// Save saves a snapshot to disk.
+// SetFallback sets a secondary backend, ideally on another mount, that
+// receives a minimal snapshot when the primary is full: the totals,
+// metadata, mount usage and the topN largest files. A topN of zero or less
+// selects DefaultFallbackTopN.
+func (s *SnapshotStore) SetFallback(backend Backend, topN int) {
+	if topN <= 0 {
+		topN = DefaultFallbackTopN
+	}
+	s.fallback = backend
+	s.fallbackTopN = topN
+}
+
+// Save saves a snapshot to the backend. encoding/json writes map keys in
+// sorted order, so identical snapshots always encode to identical bytes; the
+// indented form keeps one field per line so snapshot files diff cleanly.
+//
+// If the backend runs out of space, the error wraps ErrDiskFull. When a
+// fallback is set, a minimal snapshot is saved there under the same name
+// first and the error says whether that succeeded.
 func (s *SnapshotStore) Save(snapshot *types.Snapshot, filename string) error {
-	data, err := json.Marshal(snapshot)
+	data, err := json.MarshalIndent(snapshot, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filename, data, 0644)
+
+	err = s.backend.Put(filename, bytes.NewReader(data))
+	if err == nil || !errors.Is(err, syscall.ENOSPC) {
+		return err
+	}
+
+	if s.fallback == nil {
+		return fmt.Errorf("%w: %v", ErrDiskFull, err)
+	}
+	minimal, merr := json.MarshalIndent(minimalSnapshot(snapshot, s.fallbackTopN), "", "  ")
+	if merr == nil {
+		merr = s.fallback.Put(filename, bytes.NewReader(minimal))
+	}
+	if merr != nil {
+		return fmt.Errorf("%w: %v; fallback save also failed: %v", ErrDiskFull, err, merr)
+	}
+	return fmt.Errorf("%w: %v; saved minimal snapshot to fallback", ErrDiskFull, err)
 }
 
-// Load loads a snapshot from disk.
+// minimalSnapshot returns a copy of snapshot keeping its totals, metadata
+// and mounts but only the topN largest files.
+func minimalSnapshot(snapshot *types.Snapshot, topN int) *types.Snapshot {
+	largest := &largestFiles{n: topN}
+	for _, info := range snapshot.Files {
+		if !info.IsDir {
+			largest.add(info)
+		}
+	}
+
+	minimal := *snapshot
+	minimal.Files = make(map[string]types.FileInfo, len(largest.h))
+	for _, info := range largest.h {
+		minimal.Files[info.Path] = info
+	}
+	minimal.Dirs = nil
+	minimal.Truncated = minimal.Truncated || largest.evicted
+	return &minimal
+}
+
+// Load loads a snapshot from the backend.
 func (s *SnapshotStore) Load(filename string) (*types.Snapshot, error) {
-	data, err := os.ReadFile(filename)
+	r, err := s.backend.Get(filename)
 	if err != nil {
 		return nil, err
 	}
+	defer r.Close()
 
 	var snapshot types.Snapshot
-	if err := json.Unmarshal(data, &snapshot); err != nil {
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
 		return nil, err
 	}
 
 	return &snapshot, nil
 }
 
-// CompareSnapshots compares two snapshots and returns the differences.
-func CompareSnapshots(snap1, snap2 *types.Snapshot, thresholdBytes int64) []types.FileGrowth {
-	interval := snap2.Timestamp.Sub(snap1.Timestamp)
-	if interval <= 0 {
-		interval = time.Second
+// DiffAgainstLive loads the baseline snapshot saved as baselineFile, takes
+// a live snapshot with sc and returns the growth between them. The interval
+// runs from the baseline to now. Files created since the baseline are
+// reported as new.
+func (s *SnapshotStore) DiffAgainstLive(ctx context.Context, baselineFile string, sc *Scanner) (*types.ScanResult, error) {
+	baseline, err := s.Load(baselineFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline %s: %w", baselineFile, err)
 	}
 
-	var growing []types.FileGrowth
+	live, err := sc.TakeSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Find files that grew
-	for path, info2 := range snap2.Files {
-		if info2.IsDir {
-			continue
-		}
+	return sc.ScanWithSnapshots(baseline, live), nil
+}
 
-		info1, exists := snap1.Files[path]
-		if !exists {
-			// New file
-			if info2.Size >= thresholdBytes {
-				growing = append(growing, types.FileGrowth{
-					Path:        path,
-					InitialSize: 0,
-					FinalSize:   info2.Size,
-					GrowthBytes: info2.Size,
-					GrowthRate:  float64(info2.Size) / interval.Seconds(),
-					Interval:    interval,
-				})
-			}
-			continue
-		}
+// List returns the names of stored snapshots.
+func (s *SnapshotStore) List() ([]string, error) {
+	return s.backend.List()
+}
 
-		growth := info2.Size - info1.Size
-		if growth >= thresholdBytes {
-			growing = append(growing, types.FileGrowth{
-				Path:        path,
-				InitialSize: info1.Size,
-				FinalSize:   info2.Size,
-				GrowthBytes: growth,
-				GrowthRate:  float64(growth) / interval.Seconds(),
-				Interval:    interval,
-			})
-		}
-	}
+// Delete removes a stored snapshot.
+func (s *SnapshotStore) Delete(filename string) error {
+	return s.backend.Delete(filename)
+}
 
-	return growing
+// CompareSnapshots returns the files that grew by at least thresholdBytes
+// between two snapshots, new files included, sorted by growth rate. It
+// doesn't check that both cover the same scan paths; see CheckSnapshotPaths
+// and RestrictToCommonPaths.
+func CompareSnapshots(snap1, snap2 *types.Snapshot, thresholdBytes int64) []types.FileGrowth {
+	return GrowthCalculator{Strategy: FixedBytes(thresholdBytes)}.Calculate(snap1, snap2)
 }
@@ -0,0 +1,19 @@
+//go:build !unix
+
+package scanner
+
+import (
+	"errors"
+	"os"
+)
+
+// statIDs is only implemented on Unix systems; elsewhere files have no
+// device, inode or owner.
+func statIDs(info os.FileInfo) (dev, ino uint64, uid uint32, ok bool) {
+	return 0, 0, 0, false
+}
+
+// diskUsage is only implemented on Unix systems.
+func diskUsage(mount string) (total, free uint64, err error) {
+	return 0, 0, errors.ErrUnsupported
+}
@@ -0,0 +1,29 @@
+//go:build unix
+
+package scanner
+
+import (
+	"os"
+	"syscall"
+)
+
+// statIDs returns the device, inode and owner recorded in a FileInfo from
+// the host filesystem. ok is false for infos without a stat_t, such as
+// those from an in-memory FS.
+func statIDs(info os.FileInfo) (dev, ino uint64, uid uint32, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, 0, false
+	}
+	return uint64(st.Dev), uint64(st.Ino), st.Uid, true
+}
+
+// diskUsage returns the total and available bytes of the filesystem
+// mounted at mount.
+func diskUsage(mount string) (total, free uint64, err error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(mount, &st); err != nil {
+		return 0, 0, err
+	}
+	return uint64(st.Blocks) * uint64(st.Bsize), uint64(st.Bavail) * uint64(st.Bsize), nil
+}
@@ -0,0 +1,237 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thiruk/logmonster/internal/audit"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// sizeRecord is one line of an on-disk streaming snapshot.
+type sizeRecord struct {
+	base int
+	path string
+	size int64
+}
+
+// StreamingScan compares two walks of the configured paths without holding
+// either snapshot in memory. The first walk is written to a sorted file in
+// tmpDir and the second walk is merge-joined against it, keeping only the
+// topN fastest-growing files (all growing files when topN <= 0).
+func (s *Scanner) StreamingScan(ctx context.Context, tmpDir string, topN int) (*types.ScanResult, error) {
+	result := &types.ScanResult{
+		StartTime: time.Now(),
+		Paths:     s.config.Paths,
+		Interval:  s.config.Interval,
+	}
+	_ = s.auditLog.Record(audit.Event{Type: audit.ScanStarted, Paths: s.config.Paths})
+
+	tmp, err := os.CreateTemp(tmpDir, "logmonster-stream-*.txt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	// Both passes walk the same expansion so base indexes line up
+	paths := s.basePaths()
+
+	// First pass: write every file in walk order
+	start1 := time.Now()
+	w := bufio.NewWriter(tmp)
+	err = s.walkOrdered(ctx, paths, func(rec sizeRecord) error {
+		_, err := fmt.Fprintf(w, "%d\t%d\t%s\n", rec.base, rec.size, strconv.Quote(rec.path))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	// Wait for interval
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(s.config.Interval):
+	}
+
+	// Second pass: merge-join against the first
+	start2 := time.Now()
+	interval := start2.Sub(start1)
+	if interval <= 0 {
+		interval = time.Second
+	}
+	calc := s.calculator()
+
+	reader := newRecordReader(tmp)
+	top := &topGrowth{n: topN}
+	err = s.walkOrdered(ctx, paths, func(rec sizeRecord) error {
+		var info1 types.FileInfo // zero for a file new in the second walk
+		for {
+			prev, ok, err := reader.peek()
+			if err != nil {
+				return err
+			}
+			if !ok || compareRecords(prev, rec) > 0 {
+				break
+			}
+			reader.next()
+			if compareRecords(prev, rec) == 0 {
+				info1 = types.FileInfo{Path: prev.path, Size: prev.size}
+				break
+			}
+			// prev no longer exists in the second walk
+		}
+
+		info2 := types.FileInfo{Path: rec.path, Size: rec.size}
+		if g, ok := calc.Evaluate(rec.path, info1, info2, interval); ok {
+			top.add(g)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.EndTime = time.Now()
+	result.GrowingFiles = top.sorted()
+	result.TotalGrowth = top.total
+	for i := range result.GrowingFiles {
+		g := &result.GrowingFiles[i]
+		g.NormalizedRate = s.normalizedRate(g.GrowthRate, interval)
+	}
+	s.auditFlagged(result.GrowingFiles)
+
+	return result, nil
+}
+
+// walkOrdered walks paths sequentially in a deterministic order, calling fn
+// for every file. The order matches compareRecords.
+func (s *Scanner) walkOrdered(ctx context.Context, paths []string, fn func(sizeRecord) error) error {
+	for i, basePath := range paths {
+		base := i
+		err := walkTree(ctx, s.config, basePath, 0, func(path string, _ os.FileMode) error {
+			info, err := s.statFile(path)
+			if err != nil || info.IsDir {
+				return nil
+			}
+			return fn(sizeRecord{base: base, path: path, size: info.Size})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareRecords orders records by base path index, then by path with the
+// separator sorting before every other byte. This matches a depth-first walk
+// over name-sorted directory entries.
+func compareRecords(a, b sizeRecord) int {
+	if a.base != b.base {
+		if a.base < b.base {
+			return -1
+		}
+		return 1
+	}
+
+	n := len(a.path)
+	if len(b.path) < n {
+		n = len(b.path)
+	}
+	for i := 0; i < n; i++ {
+		ca, cb := a.path[i], b.path[i]
+		if ca == cb {
+			continue
+		}
+		if ca == filepath.Separator {
+			return -1
+		}
+		if cb == filepath.Separator {
+			return 1
+		}
+		if ca < cb {
+			return -1
+		}
+		return 1
+	}
+
+	switch {
+	case len(a.path) < len(b.path):
+		return -1
+	case len(a.path) > len(b.path):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// recordReader reads size records written by StreamingScan.
+type recordReader struct {
+	r       *bufio.Reader
+	current sizeRecord
+	loaded  bool
+	done    bool
+}
+
+func newRecordReader(r io.Reader) *recordReader {
+	return &recordReader{r: bufio.NewReader(r)}
+}
+
+// peek returns the current record without consuming it.
+func (rr *recordReader) peek() (sizeRecord, bool, error) {
+	if rr.done {
+		return sizeRecord{}, false, nil
+	}
+	if rr.loaded {
+		return rr.current, true, nil
+	}
+
+	line, err := rr.r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		rr.done = true
+		return sizeRecord{}, false, nil
+	}
+	if err != nil && err != io.EOF {
+		return sizeRecord{}, false, err
+	}
+
+	fields := strings.SplitN(strings.TrimSuffix(line, "\n"), "\t", 3)
+	if len(fields) != 3 {
+		return sizeRecord{}, false, fmt.Errorf("malformed stream record: %q", line)
+	}
+	base, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return sizeRecord{}, false, err
+	}
+	size, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return sizeRecord{}, false, err
+	}
+	path, err := strconv.Unquote(fields[2])
+	if err != nil {
+		return sizeRecord{}, false, err
+	}
+
+	rr.current = sizeRecord{base: base, path: path, size: size}
+	rr.loaded = true
+	return rr.current, true, nil
+}
+
+// next consumes the current record.
+func (rr *recordReader) next() {
+	rr.loaded = false
+}
@@ -0,0 +1,163 @@
+//go:build unix
+
+package scanner
+
+import (
+	"context"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// phasedFS serves before until first has been read twice, then after, so
+// the second pass of a StreamingScan sees the changed tree.
+type phasedFS struct {
+	before, after FS
+	first         string
+
+	mu    sync.Mutex
+	reads int
+}
+
+func (p *phasedFS) current() FS {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reads >= 2 {
+		return p.after
+	}
+	return p.before
+}
+
+func (p *phasedFS) ReadDir(name string) ([]os.DirEntry, error) {
+	if name == p.first {
+		p.mu.Lock()
+		p.reads++
+		p.mu.Unlock()
+	}
+	return p.current().ReadDir(name)
+}
+
+// Stat gives directories an inode, which the walk needs before it will
+// enter one through a symlink.
+func (p *phasedFS) Stat(name string) (os.FileInfo, error) {
+	info, err := p.current().Stat(name)
+	if err != nil || !info.IsDir() {
+		return info, err
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return inodeInfo{info, &syscall.Stat_t{Ino: h.Sum64()}}, nil
+}
+
+type inodeInfo struct {
+	os.FileInfo
+	st *syscall.Stat_t
+}
+
+func (i inodeInfo) Sys() any { return i.st }
+
+// growthByPath maps each growing file to its growth.
+func growthByPath(files []types.FileGrowth) map[string]int64 {
+	m := make(map[string]int64, len(files))
+	for _, g := range files {
+		m[g.Path] = g.GrowthBytes
+	}
+	return m
+}
+
+func TestStreamingScanMatchesSnapshots(t *testing.T) {
+	data := func(n int) *fstest.MapFile { return &fstest.MapFile{Data: make([]byte, n)} }
+	link := func(target string) *fstest.MapFile {
+		return &fstest.MapFile{Mode: fs.ModeSymlink, Data: []byte(target)}
+	}
+
+	// Names around the separator: "a-b" and "a.log" sort before "a/" bytewise
+	// but after the directory "a" in a walk. The directory "c" is new, so its
+	// file is joined against "c-d" from the first walk.
+	before := fstest.MapFS{
+		"srv/log/a/x":        data(10),
+		"srv/log/a/y/z.log":  data(10),
+		"srv/log/a-b":        data(10),
+		"srv/log/a.log":      data(10),
+		"srv/log/a0/gone":    data(10),
+		"srv/log/b.log":      data(10),
+		"srv/log/c-d":        data(10),
+		"srv/log/linked":     link("../data"),
+		"srv/data/in.log":    data(10),
+		"srv/other/a.log":    data(10),
+		"srv/other/shrunk":   data(50),
+		"srv/other/same.log": data(10),
+	}
+	after := fstest.MapFS{
+		"srv/log/a/x":        data(110),
+		"srv/log/a/y/z.log":  data(10),
+		"srv/log/a/new":      data(30),
+		"srv/log/a-b":        data(20),
+		"srv/log/a.log":      data(40),
+		"srv/log/a0/new.log": data(5),
+		"srv/log/b.log":      data(10),
+		"srv/log/c/new":      data(3),
+		"srv/log/c-d":        data(25),
+		"srv/log/linked":     link("../data"),
+		"srv/data/in.log":    data(70),
+		"srv/other/a.log":    data(60),
+		"srv/other/shrunk":   data(5),
+		"srv/other/same.log": data(10),
+		"srv/other/z.log":    data(8),
+	}
+
+	config := DefaultConfig()
+	config.Paths = []string{"/srv/log", "/srv/other"}
+	config.ThresholdBytes = 1
+	config.FollowSymlinks = true
+	config.Interval = time.Millisecond
+
+	snapOf := func(fsys fstest.MapFS) *types.Snapshot {
+		t.Helper()
+		c := config
+		c.FS = &phasedFS{before: FromIOFS(fsys), after: FromIOFS(fsys)}
+		snap, err := New(c).TakeSnapshot(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return snap
+	}
+	snap1, snap2 := snapOf(before), snapOf(after)
+	snap2.Timestamp = snap1.Timestamp.Add(time.Second)
+	want := growthByPath(New(config).ScanWithSnapshots(snap1, snap2).GrowingFiles)
+
+	// The comparison itself must see every kind of change
+	for path, growth := range map[string]int64{
+		"/srv/log/a/x":           100,
+		"/srv/log/a/new":         30,
+		"/srv/log/a-b":           10,
+		"/srv/log/a.log":         30,
+		"/srv/log/a0/new.log":    5,
+		"/srv/log/c/new":         3,
+		"/srv/log/c-d":           15,
+		"/srv/log/linked/in.log": 60,
+		"/srv/other/a.log":       50,
+		"/srv/other/z.log":       8,
+	} {
+		if want[path] != growth {
+			t.Fatalf("ScanWithSnapshots growth of %s = %d, want %d (all: %v)", path, want[path], growth, want)
+		}
+	}
+
+	config.FS = &phasedFS{before: FromIOFS(before), after: FromIOFS(after), first: "/srv/log"}
+	result, err := New(config).StreamingScan(context.Background(), t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := growthByPath(result.GrowingFiles); !reflect.DeepEqual(got, want) {
+		t.Errorf("StreamingScan growth = %v\nScanWithSnapshots growth = %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"container/heap"
+	"sort"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// growthHeap is a min-heap of file growth ordered by growth rate.
+type growthHeap []types.FileGrowth
+
+func (h growthHeap) Len() int           { return len(h) }
+func (h growthHeap) Less(i, j int) bool { return h[i].GrowthRate < h[j].GrowthRate }
+func (h growthHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *growthHeap) Push(x interface{}) {
+	*h = append(*h, x.(types.FileGrowth))
+}
+
+func (h *growthHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// topGrowth keeps the n fastest-growing files seen so far, and the growth
+// of every file offered. When n <= 0 every file is kept.
+type topGrowth struct {
+	n     int
+	h     growthHeap
+	total int64
+}
+
+// add offers a file to the selection.
+func (t *topGrowth) add(g types.FileGrowth) {
+	t.total += g.GrowthBytes
+	switch {
+	case t.n <= 0:
+		t.h = append(t.h, g)
+	case len(t.h) < t.n:
+		heap.Push(&t.h, g)
+	case g.GrowthRate > t.h[0].GrowthRate:
+		t.h[0] = g
+		heap.Fix(&t.h, 0)
+	}
+}
+
+// sorted returns the selected files ordered by growth rate descending.
+func (t *topGrowth) sorted() []types.FileGrowth {
+	result := []types.FileGrowth(t.h)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GrowthRate > result[j].GrowthRate
+	})
+	return result
+}
+
+// sizeHeap is a min-heap of files ordered by size.
+type sizeHeap []types.FileInfo
+
+func (h sizeHeap) Len() int           { return len(h) }
+func (h sizeHeap) Less(i, j int) bool { return h[i].Size < h[j].Size }
+func (h sizeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *sizeHeap) Push(x interface{}) {
+	*h = append(*h, x.(types.FileInfo))
+}
+
+func (h *sizeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
+
+// largestFiles keeps the n largest files seen so far and records whether
+// any file had to be dropped.
+type largestFiles struct {
+	n       int
+	h       sizeHeap
+	evicted bool
+}
+
+// add offers a file to the selection.
+func (l *largestFiles) add(info types.FileInfo) {
+	if len(l.h) < l.n {
+		heap.Push(&l.h, info)
+		return
+	}
+	l.evicted = true
+	if info.Size > l.h[0].Size {
+		l.h[0] = info
+		heap.Fix(&l.h, 0)
+	}
+}
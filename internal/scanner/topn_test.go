@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+func TestScanWithSnapshotsTotalsBeyondTopN(t *testing.T) {
+	config := DefaultConfig()
+	config.ThresholdBytes = 1
+	config.TopN = 2
+	s := New(config)
+
+	sizes1 := make(map[string]int64)
+	sizes2 := make(map[string]int64)
+	for i := 1; i <= 5; i++ {
+		path := fmt.Sprintf("/log/%d.log", i)
+		sizes1[path] = 0
+		sizes2[path] = int64(i * 100)
+	}
+
+	result := s.ScanWithSnapshots(snapshotOf(0, sizes1), snapshotOf(10, sizes2))
+	if len(result.GrowingFiles) != 2 {
+		t.Fatalf("got %d growing files, want TopN = 2", len(result.GrowingFiles))
+	}
+	if result.GrowingFiles[0].Path != "/log/5.log" || result.GrowingFiles[1].Path != "/log/4.log" {
+		t.Errorf("kept %s and %s, want the two fastest", result.GrowingFiles[0].Path, result.GrowingFiles[1].Path)
+	}
+	if result.TotalGrowth != 1500 {
+		t.Errorf("TotalGrowth = %d, want 1500 across all flagged files", result.TotalGrowth)
+	}
+}
+
+// BenchmarkTopGrowth compares keeping the top 10 of a million flagged files
+// in a heap against collecting and sorting all of them.
+func BenchmarkTopGrowth(b *testing.B) {
+	const files = 1_000_000
+	rng := rand.New(rand.NewSource(1))
+	growth := make([]types.FileGrowth, files)
+	for i := range growth {
+		growth[i] = types.FileGrowth{
+			Path:       fmt.Sprintf("/var/log/%07d.log", i),
+			GrowthRate: rng.Float64() * 1e6,
+		}
+	}
+
+	b.Run("heap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			top := &topGrowth{n: 10}
+			for _, g := range growth {
+				top.add(g)
+			}
+			top.sorted()
+		}
+	})
+
+	b.Run("sort", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var all []types.FileGrowth
+			for _, g := range growth {
+				all = append(all, g)
+			}
+			sort.Slice(all, func(i, j int) bool {
+				return all[i].GrowthRate > all[j].GrowthRate
+			})
+			_ = all[:10]
+		}
+	})
+}
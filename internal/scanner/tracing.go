@@ -0,0 +1,39 @@
+package scanner
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName identifies the instrumentation scope of scanner spans.
+const tracerName = "github.com/thiruk/logmonster/internal/scanner"
+
+// WithTracer enables OpenTelemetry spans around the scan phases: the whole
+// Scan, each TakeSnapshot, the interval wait and CalculateGrowth. Without
+// it tracing is a no-op. It returns s for chaining after New.
+func (s *Scanner) WithTracer(tp trace.TracerProvider) *Scanner {
+	s.tracer = tp.Tracer(tracerName)
+	return s
+}
+
+// startSpan starts a span, using a no-op tracer when none is configured.
+func (s *Scanner) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := s.tracer
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
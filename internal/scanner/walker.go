@@ -2,8 +2,11 @@ package scanner
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"syscall"
 
 	"github.com/thiruk/logmonster/pkg/types"
 )
@@ -24,69 +27,217 @@ func NewWalker(config Config) *Walker {
 	}
 }
 
-// Walk walks all configured paths and returns file information.
+// Walk walks all configured paths and returns file information. It visits
+// exactly the files a Scanner snapshot would.
 func (w *Walker) Walk(ctx context.Context, paths []string) ([]types.FileInfo, error) {
 	var files []types.FileInfo
 
 	for _, basePath := range paths {
-		err := filepath.WalkDir(basePath, func(path string, d os.DirEntry, err error) error {
+		err := walkTree(ctx, w.config, basePath, 0, func(path string, _ os.FileMode) error {
+			info, err := statRetry(w.config.fs(), path)
 			if err != nil {
-				// Skip paths we can't access
-				return nil
+				return nil // Skip paths we can't access
 			}
+			files = append(files, fileInfoFromOS(path, info))
+			return nil
+		})
 
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			default:
-			}
+		if err != nil && err != context.Canceled {
+			return nil, err
+		}
+	}
 
-			// Skip directories themselves
-			if d.IsDir() {
-				return nil
-			}
+	return files, nil
+}
 
-			// Skip symlinks if configured
-			if d.Type()&os.ModeSymlink != 0 && !w.config.FollowSymlinks {
-				return nil
-			}
+// filterFile reports whether a directory entry should be visited and
+// whether it is a directory to descend into, applying the symlink, exclude
+// pattern and excluded directory settings. Symlinks are judged by their
+// target. Every walk filters entries through it so they all agree on what is
+// scanned.
+func (c Config) filterFile(path string, entry os.DirEntry) (visit, isDir bool) {
+	isLink := entry.Type()&os.ModeSymlink != 0
+	isDir = entry.IsDir()
+
+	var target os.FileInfo
+	if isLink {
+		var ok bool
+		if target, ok = c.followLink(path); !ok {
+			return false, false
+		}
+		isDir = target.IsDir()
+	}
 
-			// Check exclude patterns
-			if w.isExcluded(d.Name()) {
-				return nil
-			}
+	// Check exclude patterns
+	if matchExclude(c.ExcludePatterns, path) {
+		return false, false
+	}
 
-			info, err := d.Info()
-			if err != nil {
-				return nil
-			}
+	if isDir && matchExcludeDir(c.ExcludeDirs, path) {
+		return false, false
+	}
 
-			files = append(files, types.FileInfo{
-				Path:       path,
-				Size:       info.Size(),
-				ModTime:    info.ModTime(),
-				IsDir:      info.IsDir(),
-				Permission: uint32(info.Mode().Perm()),
-			})
+	// Don't cross into other filesystems
+	if isDir && c.StayOnDevice {
+		if dev, err := deviceOf(c.fs(), path); err != nil || dev != c.rootDevice {
+			return false, false
+		}
+	}
 
-			return nil
-		})
+	// Enter each linked directory once, so links back up the tree can't loop
+	if isLink && isDir && !c.firstVisit(target) {
+		return false, false
+	}
 
-		if err != nil && err != context.Canceled {
-			return nil, err
+	return true, isDir
+}
+
+// followDirLinks reports whether symlinked directories are descended into.
+func (c Config) followDirLinks() bool {
+	return c.FollowSymlinks || c.FollowDirSymlinks
+}
+
+// followFileLinks reports whether symlinked files are scanned.
+func (c Config) followFileLinks() bool {
+	return c.FollowSymlinks || c.FollowFileSymlinks
+}
+
+// followLink stats a symlink's target and reports whether the settings allow
+// following it. Broken links are never followed.
+func (c Config) followLink(path string) (os.FileInfo, bool) {
+	if !c.followDirLinks() && !c.followFileLinks() {
+		return nil, false
+	}
+	info, err := c.fs().Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if info.IsDir() {
+		return info, c.followDirLinks()
+	}
+	return info, c.followFileLinks()
+}
+
+// dirKey identifies a directory independent of the path it was reached by.
+type dirKey struct {
+	dev, ino uint64
+}
+
+// firstVisit records a linked directory and reports whether the walk has
+// not entered it yet. Directories without an inode are never entered, as
+// there is no safe way to detect a cycle through them.
+func (c Config) firstVisit(info os.FileInfo) bool {
+	dev, ino, _, ok := statIDs(info)
+	if !ok || c.linkedDirs == nil {
+		return false
+	}
+	key := dirKey{dev: dev, ino: ino}
+	if c.linkedDirs[key] {
+		return false
+	}
+	c.linkedDirs[key] = true
+	return true
+}
+
+// noteSkipped counts an entry skipped with err if its path was too long.
+func (c Config) noteSkipped(err error) {
+	if c.longPaths != nil && isNameTooLong(err) {
+		c.longPaths.Add(1)
+	}
+}
+
+// rooted returns a copy of the config for walking root, recording root's
+// device when StayOnDevice is set and starting a fresh set of linked
+// directories when they are followed.
+func (c Config) rooted(root string) Config {
+	if c.StayOnDevice {
+		c.rootDevice, _ = deviceOf(c.fs(), root)
+	}
+	if c.followDirLinks() {
+		c.linkedDirs = make(map[dirKey]bool)
+		if info, err := c.fs().Stat(root); err == nil {
+			c.firstVisit(info)
 		}
 	}
+	return c
+}
 
-	return files, nil
+// walkTree walks path depth-first in name order, honouring MaxDepth and
+// filterFile, and calls fn for every entry that is not a directory with the
+// type ReadDir reported for it. A directory replaced by a file before it
+// could be read is passed to fn instead. Unreadable directories are skipped.
+// Walking stops at the first error from fn or when ctx is cancelled.
+func walkTree(ctx context.Context, c Config, path string, depth int, fn func(path string, typ os.FileMode) error) error {
+	return walkTreeHooks(ctx, c, path, depth, walkHooks{}, fn)
 }
 
-// isExcluded checks if a filename matches any exclude pattern.
-func (w *Walker) isExcluded(name string) bool {
-	for _, pattern := range w.config.ExcludePatterns {
-		matched, _ := filepath.Match(pattern, name)
-		if matched {
-			return true
+// walkHooks adjust a walkTree walk. Either may be nil.
+type walkHooks struct {
+	// skipFiles reports whether fn should not be called for a directory's
+	// own files. Its subdirectories are still walked.
+	skipFiles func(dir string) bool
+
+	// leave is called once a directory and everything below it have been
+	// walked. Walking stops if it returns an error.
+	leave func(dir string) error
+}
+
+// walkTreeHooks is walkTree with hooks.
+func walkTreeHooks(ctx context.Context, c Config, path string, depth int, hooks walkHooks, fn func(path string, typ os.FileMode) error) error {
+	if c.MaxDepth > 0 && depth > c.MaxDepth {
+		return nil
+	}
+	if depth == 0 {
+		c = c.rooted(path)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	// FS.ReadDir returns entries sorted by name
+	entries, err := c.fs().ReadDir(path)
+	if errors.Is(err, syscall.ENOTDIR) && depth > 0 {
+		// Replaced by a file since its parent was read
+		c.logger().Debug("directory became a file during the walk", slog.String("path", path))
+		if hooks.skipFiles != nil && hooks.skipFiles(filepath.Dir(path)) {
+			return nil
+		}
+		return fn(path, 0)
+	}
+	if err != nil {
+		c.noteSkipped(err)
+		return nil // Skip directories we can't read
+	}
+
+	skipFiles := hooks.skipFiles != nil && hooks.skipFiles(path)
+	for _, entry := range entries {
+		fullPath := filepath.Join(path, entry.Name())
+
+		visit, isDir := c.filterFile(fullPath, entry)
+		if !visit {
+			continue
 		}
+
+		if isDir {
+			if err := walkTreeHooks(ctx, c, fullPath, depth+1, hooks, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if skipFiles {
+			continue
+		}
+		if err := fn(fullPath, entry.Type()); err != nil {
+			return err
+		}
+	}
+
+	if hooks.leave != nil {
+		return hooks.leave(path)
 	}
-	return false
+	return nil
 }
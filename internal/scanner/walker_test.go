@@ -0,0 +1,103 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// makeLinkTree creates a tree exercising excludes, depth and symlinks:
+//
+//	root/app.log
+//	root/app.log.gz
+//	root/cache/tmp.dat
+//	root/a/b/c/deep.log
+//	root/link.log -> app.log
+//	root/linkdir -> a
+//	root/a/loop -> root
+//	root/broken -> missing
+func makeLinkTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+
+	for _, dir := range []string{"cache", "a/b/c"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"app.log", "app.log.gz", "cache/tmp.dat", "a/b/c/deep.log"} {
+		if err := os.WriteFile(filepath.Join(root, file), []byte(file), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"link.log": "app.log",
+		"linkdir":  "a",
+		"a/loop":   root,
+		"broken":   "missing",
+	}
+	for link, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+func TestWalkerMatchesSnapshot(t *testing.T) {
+	root := makeLinkTree(t)
+
+	tests := []struct {
+		name   string
+		config func(*Config)
+		count  int // files both walks must find
+	}{
+		{"defaults", func(c *Config) {}, 4},
+		{"exclude patterns", func(c *Config) { c.ExcludePatterns = []string{"*.gz", filepath.Join(root, "a/b/c/*")} }, 2},
+		{"exclude dirs", func(c *Config) { c.ExcludeDirs = []string{filepath.Join(root, "cache")} }, 3},
+		{"max depth", func(c *Config) { c.MaxDepth = 1 }, 3},
+		{"follow dir symlinks", func(c *Config) { c.FollowDirSymlinks = true }, 5},
+		{"follow file symlinks", func(c *Config) { c.FollowFileSymlinks = true }, 5},
+		{"follow symlinks", func(c *Config) { c.FollowSymlinks = true }, 6},
+		{"follow symlinks, depth 2", func(c *Config) { c.FollowSymlinks = true; c.MaxDepth = 2 }, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.Paths = []string{root}
+			tt.config(&config)
+
+			walked, err := NewWalker(config).Walk(context.Background(), config.Paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fromWalker []string
+			for _, info := range walked {
+				fromWalker = append(fromWalker, info.Path)
+			}
+			sort.Strings(fromWalker)
+
+			snapshot, err := New(config).TakeSnapshot(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fromSnapshot []string
+			for path := range snapshot.Files {
+				fromSnapshot = append(fromSnapshot, path)
+			}
+			sort.Strings(fromSnapshot)
+
+			if len(fromWalker) != tt.count {
+				t.Errorf("Walker found %d files, want %d: %v", len(fromWalker), tt.count, fromWalker)
+			}
+			if !reflect.DeepEqual(fromWalker, fromSnapshot) {
+				t.Errorf("Walker found %v\nTakeSnapshot found %v", fromWalker, fromSnapshot)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// WatchFile stats a single file every interval and emits its growth for each
+// interval. When the file is truncated or recreated, growth is measured from
+// zero. Intervals in which the file is missing are skipped. The channel is
+// closed when ctx is done.
+func WatchFile(ctx context.Context, path string, interval time.Duration) (<-chan types.FileGrowth, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	prev := fileInfoFromOS(path, info)
+	prevTime := time.Now()
+
+	out := make(chan types.FileGrowth)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil {
+					continue // Missing for now; may be recreated
+				}
+				cur := fileInfoFromOS(path, info)
+
+				initial := prev.Size
+				recreated := prev.Inode != 0 && cur.Inode != 0 && prev.Inode != cur.Inode
+				if recreated || cur.Size < prev.Size {
+					initial = 0
+				}
+
+				elapsed := now.Sub(prevTime)
+				if elapsed <= 0 {
+					elapsed = interval
+				}
+				growth := cur.Size - initial
+
+				g := types.FileGrowth{
+					Path:        path,
+					InitialSize: initial,
+					FinalSize:   cur.Size,
+					GrowthBytes: growth,
+					GrowthRate:  float64(growth) / elapsed.Seconds(),
+					Interval:    elapsed,
+				}
+
+				prev, prevTime = cur, now
+
+				select {
+				case out <- g:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
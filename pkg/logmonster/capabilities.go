@@ -0,0 +1,49 @@
+// Package logmonster provides the public entry points for embedding
+// logmonster.
+package logmonster
+
+import (
+	"os"
+
+	"github.com/thiruk/logmonster/internal/mapper"
+	"github.com/thiruk/logmonster/internal/scanner"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// CheckCapabilities reports which optional system features are available to
+// this process, so that degraded operation can be explained up front.
+func CheckCapabilities() []types.Capability {
+	return []types.Capability{
+		{
+			Name:      "root",
+			Available: os.Geteuid() == 0,
+			Impact:    "process mapping will be incomplete for other users' processes",
+		},
+		{
+			Name:      "dbus",
+			Available: hasSystemBus(),
+			Impact:    "service names will be guessed from the process tree",
+		},
+		{
+			Name:      "lsof",
+			Available: mapper.LsofAvailable(),
+			Impact:    "process mapping falls back to a slower /proc scan",
+		},
+		{
+			Name:      "inotify",
+			Available: hasInotify(),
+			Impact:    "file changes are detected by polling only",
+		},
+		{
+			Name:      "fanotify",
+			Available: hasFanotify(),
+			Impact:    "mount-wide write monitoring is unavailable; scans poll instead",
+		},
+	}
+}
+
+// hasFanotify reports whether fanotify can be initialised.
+func hasFanotify() bool {
+	_, err := scanner.NewFanotifyWatcher(scanner.Config{})
+	return err == nil
+}
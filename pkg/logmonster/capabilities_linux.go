@@ -0,0 +1,28 @@
+//go:build linux
+
+package logmonster
+
+import (
+	"github.com/godbus/dbus/v5"
+	"golang.org/x/sys/unix"
+)
+
+// hasInotify reports whether an inotify instance can be created.
+func hasInotify() bool {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC)
+	if err != nil {
+		return false
+	}
+	unix.Close(fd)
+	return true
+}
+
+// hasSystemBus reports whether the D-Bus system bus is reachable.
+func hasSystemBus() bool {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package logmonster
+
+// hasInotify reports whether an inotify instance can be created. inotify is
+// Linux-only.
+func hasInotify() bool {
+	return false
+}
+
+// hasSystemBus reports whether the D-Bus system bus is reachable. logmonster
+// only talks to systemd over D-Bus, which is Linux-only.
+func hasSystemBus() bool {
+	return false
+}
@@ -0,0 +1,109 @@
+package logmonster
+
+import (
+	"context"
+	"time"
+
+	"github.com/thiruk/logmonster/internal/scanner"
+	"github.com/thiruk/logmonster/pkg/types"
+)
+
+// Monitor detects rapidly growing files. It wraps logmonster's scanner so
+// other programs can embed growth detection without the CLI.
+type Monitor struct {
+	scanner *scanner.Scanner
+}
+
+// Option configures a Monitor.
+type Option func(*options)
+
+// options holds the configuration built up by Options.
+type options struct {
+	config scanner.Config
+
+	baseline       bool
+	baselineWindow int
+	sigma          float64
+}
+
+// WithPaths sets the directories to scan. Glob patterns are re-expanded on
+// every scan. The default is /var/log and /tmp.
+func WithPaths(paths ...string) Option {
+	return func(o *options) {
+		o.config.Paths = paths
+	}
+}
+
+// WithThreshold sets the minimum growth in bytes for a file to be
+// reported. The default is 10 MB.
+func WithThreshold(bytes int64) Option {
+	return func(o *options) {
+		o.config.ThresholdBytes = bytes
+	}
+}
+
+// WithInterval sets the time between the two snapshots of a scan. The
+// default is 5 seconds.
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.config.Interval = d
+	}
+}
+
+// WithExcludes adds file patterns to skip. Bare patterns match the file
+// name; patterns containing "/" match the full path.
+func WithExcludes(patterns ...string) Option {
+	return func(o *options) {
+		o.config.ExcludePatterns = append(o.config.ExcludePatterns, patterns...)
+	}
+}
+
+// WithBaseline flags files growing more than sigma standard deviations
+// above their own recent rate, tracked over the last window scans, instead
+// of applying the threshold. Zero values use the defaults of 30 scans and 3
+// standard deviations. A file needs a few scans of history before it can be
+// flagged.
+func WithBaseline(window int, sigma float64) Option {
+	return func(o *options) {
+		o.baseline = true
+		o.baselineWindow = window
+		o.sigma = sigma
+	}
+}
+
+// NewMonitor creates a Monitor with the default configuration adjusted by
+// opts.
+func NewMonitor(opts ...Option) *Monitor {
+	o := &options{config: scanner.DefaultConfig()}
+	for _, opt := range opts {
+		opt(o)
+	}
+	s := scanner.New(o.config)
+	if o.baseline {
+		s.SetThresholdStrategy(scanner.NewBaselineTracker(o.baselineWindow, o.sigma))
+	}
+	return &Monitor{scanner: s}
+}
+
+// Scan takes two snapshots the configured interval apart and returns the
+// files that grew past the threshold, fastest first.
+func (m *Monitor) Scan(ctx context.Context) (*types.ScanResult, error) {
+	return m.scanner.Scan(ctx)
+}
+
+// WarmupScan is Scan that doesn't report files created between the two
+// snapshots, since they have no baseline yet. Use it for the first scan of
+// a loop to avoid a burst of false positives at startup.
+func (m *Monitor) WarmupScan(ctx context.Context) (*types.ScanResult, error) {
+	return m.scanner.WarmupScan(ctx)
+}
+
+// Snapshot records the size of every file under the configured paths.
+func (m *Monitor) Snapshot(ctx context.Context) (*types.Snapshot, error) {
+	return m.scanner.TakeSnapshot(ctx)
+}
+
+// Compare returns the growth between two snapshots taken by Snapshot.
+func (m *Monitor) Compare(snap1, snap2 *types.Snapshot) *types.ScanResult {
+	return m.scanner.ScanWithSnapshots(snap1, snap2)
+}
@@ -8,25 +8,106 @@ type FileInfo struct {
 	Size       int64
 	ModTime    time.Time
 	IsDir      bool
-	Permission uint32
+	Permission uint32 // Unix mode bits including setuid (04000), setgid (02000) and sticky (01000)
+	Inode      uint64
+	UID        uint32 // owner user ID; 0 on platforms without it
+
+	SelfGenerated  bool   `json:",omitempty"` // written by logmonster itself when the snapshot was taken
+	FilesystemType string `json:",omitempty"` // type of the mount holding the file, e.g. "tmpfs"
 }
 
 // FileGrowth represents the growth of a file between two snapshots.
 type FileGrowth struct {
-	Path        string
-	InitialSize int64
-	FinalSize   int64
+	Path           string
+	IsDir          bool // a directory's aggregate size rather than a single file
+	InitialSize    int64
+	FinalSize      int64
+	GrowthBytes    int64
+	GrowthRate     float64 // bytes per second
+	NormalizedRate float64 // bytes per configured scan interval, comparable across cycles
+	Interval       time.Duration
+	ZScore         float64 // deviation from the file's own baseline, if tracked
+	SelfGenerated  bool    // written by logmonster itself
+	FilesystemType string  // e.g. "ext4" or "tmpfs"; empty if unknown
+	Reason         string  // why the file was flagged, e.g. "grew +45.0 MB"
+}
+
+// BudgetBreach represents cumulative growth over a window exceeding a budget.
+type BudgetBreach struct {
+	Path        string // empty for the total across all files
+	GrowthBytes int64
+	BudgetBytes int64
+	Window      time.Duration
+}
+
+// ChurnHotspot represents a directory with high file turnover.
+type ChurnHotspot struct {
+	Dir          string
+	Creations    int
+	Deletions    int
+	CreationRate float64 // files per second
+	DeletionRate float64 // files per second
+	NetBytes     int64   // size change of the directory's files over the history
+}
+
+// SizeSample represents a file's size at a point in time.
+type SizeSample struct {
+	Timestamp time.Time
+	Size      int64
+}
+
+// ExtGrowth represents aggregated growth for files sharing an extension.
+type ExtGrowth struct {
+	Extension   string
+	FileCount   int
 	GrowthBytes int64
 	GrowthRate  float64 // bytes per second
-	Interval    time.Duration
 }
 
 // Snapshot represents a point-in-time snapshot of files.
 type Snapshot struct {
 	Timestamp time.Time
 	Files     map[string]FileInfo
+	Dirs      map[string]int64 `json:",omitempty"` // aggregate size of each directory's files; only when directory tracking is on
 	TotalSize int64
 	FileCount int
+	Metadata  *SnapshotMetadata     `json:",omitempty"` // nil for older snapshots
+	Mounts    map[string]MountUsage `json:",omitempty"` // keyed by mount point
+	Truncated bool                  `json:",omitempty"` // only the largest files were kept
+
+	LongPathsSkipped int `json:",omitempty"` // entries skipped because their path exceeded the OS limit
+}
+
+// SnapshotCounters holds the totals of a snapshot without per-file detail.
+type SnapshotCounters struct {
+	Timestamp   time.Time
+	TotalSize   int64
+	FileCount   int
+	LargestFile int64 // size of the largest file
+	LargestPath string
+}
+
+// MountUsage represents filesystem capacity at snapshot time.
+// For tmpfs the capacity is the tmpfs size limit, which is backed by RAM.
+type MountUsage struct {
+	TotalBytes     uint64
+	FreeBytes      uint64
+	FilesystemType string `json:",omitempty"`
+}
+
+// SnapshotMetadata describes where and how a snapshot was taken.
+type SnapshotMetadata struct {
+	Hostname           string
+	KernelVersion      string
+	Version            string
+	Paths              []string
+	ExcludePatterns    []string
+	ExcludeDirs        []string `json:",omitempty"`
+	ThresholdBytes     int64
+	MaxDepth           int
+	FollowSymlinks     bool
+	FollowDirSymlinks  bool `json:",omitempty"`
+	FollowFileSymlinks bool `json:",omitempty"`
 }
 
 // ProcessInfo represents information about a process.
@@ -40,6 +121,19 @@ type ProcessInfo struct {
 	CPUPercent float64
 	MemoryMB   float64
 	WriteBytes int64
+	WriteRate  float64 // bytes per second, when sampled over an interval
+	Unit       string  // owning systemd unit, when resolved
+
+	DiskWriteBytes int64         // WriteBytes less writes cancelled before reaching disk
+	BlockIODelay   time.Duration // time spent blocked on disk I/O; 0 without delay accounting
+}
+
+// OpenFile represents a regular file held open by a process.
+type OpenFile struct {
+	FD      int
+	Path    string
+	Size    int64
+	Deleted bool // unlinked but still open, so its space is not freed
 }
 
 // ServiceInfo represents information about a systemd service.
@@ -51,6 +145,21 @@ type ServiceInfo struct {
 	Description string
 }
 
+// Capability represents an optional system feature logmonster relies on.
+type Capability struct {
+	Name      string
+	Available bool
+	Impact    string // what degrades when the capability is unavailable
+}
+
+// FileAttribution links a growing file to the processes and services
+// writing to it.
+type FileAttribution struct {
+	Growth    FileGrowth
+	Processes []ProcessInfo
+	Services  []ServiceInfo
+}
+
 // ScanResult represents the result of a scan operation.
 type ScanResult struct {
 	StartTime    time.Time
@@ -59,28 +168,137 @@ type ScanResult struct {
 	Snapshot1    *Snapshot
 	Snapshot2    *Snapshot
 	GrowingFiles []FileGrowth
+	RemovedFiles []FileInfo // last-known info, only when removal reporting is enabled
 	TotalGrowth  int64
 	Paths        []string
+	Warnings     []string `json:",omitempty"` // problems that may make the result misleading
+}
+
+// GrowthDiff compares one path's growth on a problem host and a healthy
+// peer. A zero rate means the path wasn't growing on that side.
+type GrowthDiff struct {
+	Path         string
+	ProblemRate  float64 // bytes per second
+	HealthyRate  float64 // bytes per second
+	ProblemBytes int64
+	HealthyBytes int64
+}
+
+// OnlyProblem reports whether the path grew only on the problem host.
+func (d GrowthDiff) OnlyProblem() bool {
+	return d.ProblemRate > 0 && d.HealthyRate <= 0
+}
+
+// OnlyHealthy reports whether the path grew only on the healthy host.
+func (d GrowthDiff) OnlyHealthy() bool {
+	return d.HealthyRate > 0 && d.ProblemRate <= 0
+}
+
+// LogicalGrowth is the growth of a logical log: the active file and its
+// rotations, e.g. app.log, app.log.1 and app.log.2.gz in one directory.
+type LogicalGrowth struct {
+	Pattern     string // the pattern that groups the files
+	Dir         string
+	Files       int     // files in the group now
+	GrowthBytes int64   // output written during the last interval
+	GrowthRate  float64 // bytes per second
+	TotalBytes  int64   // output written since tracking started
+	Rotations   int     // files renamed or truncated by rotation since tracking started
+}
+
+// SessionReport summarizes every scan of a monitoring session.
+type SessionReport struct {
+	Start       time.Time
+	End         time.Time
+	Scans       int
+	TotalGrowth int64
+	Files       []SessionFile // most frequently flagged first
+}
+
+// SessionFile is one file's activity across a session.
+type SessionFile struct {
+	Path        string
+	Occurrences int     // scans in which the file was flagged
+	PeakRate    float64 // bytes per second
+	TotalGrowth int64
+	FirstSeen   time.Time
+	LastSeen    time.Time
+}
+
+// GrowthPattern describes how a file's size changes across snapshots.
+type GrowthPattern int
+
+const (
+	GrowthStable  GrowthPattern = iota // size and mtime unchanged
+	GrowthAppend                       // size only increases
+	GrowthRewrite                      // size oscillates or content replaced in place
+	GrowthRotated                      // inode changed or file truncated once
+)
+
+// String returns the name of the pattern.
+func (p GrowthPattern) String() string {
+	switch p {
+	case GrowthAppend:
+		return "append"
+	case GrowthRewrite:
+		return "rewrite"
+	case GrowthRotated:
+		return "rotated"
+	default:
+		return "stable"
+	}
 }
 
 // SeverityLevel represents the severity of file growth.
+// It is the index of a tier in a SeverityScheme.
 type SeverityLevel int
 
+// Levels of the default scheme; use SeverityScheme.Level to classify a rate.
 const (
 	SeverityLow    SeverityLevel = iota // < 1 MB/s
 	SeverityMedium                      // < 10 MB/s
 	SeverityHigh                        // >= 10 MB/s
 )
 
-// GetSeverity returns the severity level based on growth rate (bytes/sec).
-func GetSeverity(bytesPerSec float64) SeverityLevel {
-	mbPerSec := bytesPerSec / (1024 * 1024)
-	switch {
-	case mbPerSec >= 10:
-		return SeverityHigh
-	case mbPerSec >= 1:
-		return SeverityMedium
-	default:
-		return SeverityLow
+// SeverityTier describes one tier of a severity scheme.
+type SeverityTier struct {
+	Name    string
+	MinRate float64 // bytes per second at which the tier starts
+	Emoji   string
+	Color   string // hex color, e.g. "#FF0000"
+}
+
+// SeverityScheme is an ordered list of tiers, lowest first.
+type SeverityScheme struct {
+	Tiers []SeverityTier
+}
+
+// DefaultSeverityScheme returns the standard green/yellow/red scheme.
+func DefaultSeverityScheme() SeverityScheme {
+	return SeverityScheme{
+		Tiers: []SeverityTier{
+			{Name: "low", MinRate: 0, Emoji: "🟢", Color: "#00FF00"},
+			{Name: "medium", MinRate: 1 * 1024 * 1024, Emoji: "🟡", Color: "#FFFF00"},
+			{Name: "high", MinRate: 10 * 1024 * 1024, Emoji: "🔴", Color: "#FF0000"},
+		},
+	}
+}
+
+// Level returns the highest tier whose MinRate the rate (bytes/sec) reaches.
+func (s SeverityScheme) Level(bytesPerSec float64) SeverityLevel {
+	level := 0
+	for i, tier := range s.Tiers {
+		if bytesPerSec >= tier.MinRate {
+			level = i
+		}
+	}
+	return SeverityLevel(level)
+}
+
+// Tier returns the tier for a rate (bytes/sec).
+func (s SeverityScheme) Tier(bytesPerSec float64) SeverityTier {
+	if len(s.Tiers) == 0 {
+		return SeverityTier{}
 	}
+	return s.Tiers[s.Level(bytesPerSec)]
 }
@@ -0,0 +1,176 @@
+package util
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrUnknownSize is returned when a compressed file doesn't record its
+// uncompressed size.
+var ErrUnknownSize = errors.New("uncompressed size not recorded")
+
+// ErrNotCompressed is returned for files that are neither gzip nor zstd.
+var ErrNotCompressed = errors.New("not a gzip or zstd file")
+
+const (
+	zstdMagic          = 0xFD2FB528
+	zstdSkippableMask  = 0xFFFFFFF0
+	zstdSkippableMagic = 0x184D2A50
+)
+
+// UncompressedSize returns the uncompressed size of a gzip or zstd file
+// without decompressing it. For gzip it reads the ISIZE trailer, which holds
+// the size modulo 4 GiB and covers only the last member of a concatenated
+// file. For zstd it sums the content size recorded in each frame header,
+// skipping over compressed blocks; frames written without a content size
+// yield ErrUnknownSize.
+func UncompressedSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var magic [4]byte
+	if _, err := io.ReadFull(f, magic[:]); err != nil {
+		return 0, ErrNotCompressed
+	}
+
+	switch {
+	case magic[0] == 0x1f && magic[1] == 0x8b:
+		return gzipSize(f)
+	case binary.LittleEndian.Uint32(magic[:]) == zstdMagic:
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+		return zstdSize(bufio.NewReader(f))
+	default:
+		return 0, ErrNotCompressed
+	}
+}
+
+// gzipSize reads the ISIZE field from the last four bytes of a gzip file.
+func gzipSize(f *os.File) (int64, error) {
+	var trailer [4]byte
+	if _, err := f.Seek(-4, io.SeekEnd); err != nil {
+		return 0, err
+	}
+	if _, err := io.ReadFull(f, trailer[:]); err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint32(trailer[:])), nil
+}
+
+// zstdSize walks the frames of a zstd stream, summing their content sizes.
+func zstdSize(r *bufio.Reader) (int64, error) {
+	var total int64
+	for {
+		var magic [4]byte
+		if _, err := io.ReadFull(r, magic[:]); err != nil {
+			if err == io.EOF {
+				return total, nil
+			}
+			return 0, err
+		}
+
+		m := binary.LittleEndian.Uint32(magic[:])
+		switch {
+		case m&zstdSkippableMask == zstdSkippableMagic:
+			var size [4]byte
+			if _, err := io.ReadFull(r, size[:]); err != nil {
+				return 0, err
+			}
+			if _, err := r.Discard(int(binary.LittleEndian.Uint32(size[:]))); err != nil {
+				return 0, err
+			}
+		case m == zstdMagic:
+			n, err := zstdFrame(r)
+			if err != nil {
+				return 0, err
+			}
+			total += n
+		default:
+			return 0, fmt.Errorf("invalid zstd frame magic %#x", m)
+		}
+	}
+}
+
+// zstdFrame reads one frame after its magic number and returns its content
+// size from the frame header.
+func zstdFrame(r *bufio.Reader) (int64, error) {
+	desc, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	fcsFlag := desc >> 6
+	singleSegment := desc&0x20 != 0
+	hasChecksum := desc&0x04 != 0
+	dictIDSizes := [4]int{0, 1, 2, 4}
+
+	skip := dictIDSizes[desc&0x03]
+	if !singleSegment {
+		skip++ // window descriptor
+	}
+	if _, err := r.Discard(skip); err != nil {
+		return 0, err
+	}
+
+	var fcsSize int
+	switch fcsFlag {
+	case 0:
+		if singleSegment {
+			fcsSize = 1
+		}
+	case 1:
+		fcsSize = 2
+	case 2:
+		fcsSize = 4
+	case 3:
+		fcsSize = 8
+	}
+	if fcsSize == 0 {
+		return 0, ErrUnknownSize
+	}
+
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:fcsSize]); err != nil {
+		return 0, err
+	}
+	size := int64(binary.LittleEndian.Uint64(buf[:]))
+	if fcsSize == 2 {
+		size += 256
+	}
+
+	// Skip the blocks to reach the next frame
+	for {
+		var hdr [3]byte
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return 0, err
+		}
+		h := uint32(hdr[0]) | uint32(hdr[1])<<8 | uint32(hdr[2])<<16
+		last := h&1 != 0
+		blockType := (h >> 1) & 0x03
+		blockSize := int(h >> 3)
+		if blockType == 1 {
+			blockSize = 1 // RLE blocks store a single byte
+		}
+		if _, err := r.Discard(blockSize); err != nil {
+			return 0, err
+		}
+		if last {
+			break
+		}
+	}
+	if hasChecksum {
+		if _, err := r.Discard(4); err != nil {
+			return 0, err
+		}
+	}
+
+	return size, nil
+}
@@ -0,0 +1,6 @@
+package util
+
+import "errors"
+
+// ErrLocked is returned when another process holds the lock.
+var ErrLocked = errors.New("another instance is already running")
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package util
+
+import "fmt"
+
+// AcquireLock needs flock and is only supported on Unix systems.
+func AcquireLock(path string) (release func(), err error) {
+	return nil, fmt.Errorf("locking %s is only supported on Unix systems", path)
+}